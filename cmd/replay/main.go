@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"k8sbot/internal/server"
+	"os"
+)
+
+func main() {
+	eventsFlag := flag.String("events", "-", "The file with the recorded events, - reads them from stdin.")
+
+	// The configuration parses the flags
+	srv := server.NewServer()
+
+	var reader io.Reader = os.Stdin
+
+	if *eventsFlag != "-" {
+		file, err := os.Open(*eventsFlag)
+
+		if err != nil {
+			panic(err)
+		}
+
+		defer file.Close()
+
+		reader = file
+	}
+
+	if err := srv.Replay(reader); err != nil {
+		panic(err)
+	}
+}
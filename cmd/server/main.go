@@ -1,11 +1,26 @@
 package main
 
-import "k8sbot/internal/server"
+import (
+	"context"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/server"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+
 	srv := server.NewServer()
+	err := srv.Start(ctx)
+
+	stop()
 
-	if err := srv.Start(); err != nil {
-		panic(err)
+	// Start sets the configured logger as default
+	if err != nil {
+		slog.Error("cannot run server", logging.Err(err))
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,9 @@
+package actiontoken
+
+type InvalidTokenErr struct {
+	Reason string
+}
+
+func (i *InvalidTokenErr) Error() string {
+	return "invalid action token: " + i.Reason
+}
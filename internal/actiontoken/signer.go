@@ -0,0 +1,71 @@
+package actiontoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Signer creates and verifies HMAC-signed tokens for post actions.
+// A token is bound to a report, an action, the channel of the post
+// and an expiry time, so a token can neither be reused for another
+// report, in another channel nor forever.
+type Signer struct {
+	secret   []byte
+	validity time.Duration
+}
+
+func NewSigner(secret string, validity time.Duration) *Signer {
+	return &Signer{
+		secret:   []byte(secret),
+		validity: validity,
+	}
+}
+
+// Sign creates a new token for the given report, action and channel.
+// The returned expiry has to be sent back together with the
+// token, when the action gets executed.
+func (s *Signer) Sign(reportID, action, channelID string) (token, expires string) {
+	expires = strconv.FormatInt(time.Now().Add(s.validity).Unix(), 10)
+
+	return s.signature(reportID, action, channelID, expires), expires
+}
+
+// Verify checks if the token was created by this signer for the
+// given report, action and channel and is not expired yet. When the
+// check fails, an InvalidTokenErr will be returned.
+func (s *Signer) Verify(reportID, action, channelID, expires, token string) error {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+
+	if err != nil {
+		return &InvalidTokenErr{Reason: fmt.Sprintf("invalid expiry %v", expires)}
+	}
+
+	given, err := hex.DecodeString(token)
+
+	if err != nil {
+		return &InvalidTokenErr{Reason: "malformed signature"}
+	}
+
+	expected, _ := hex.DecodeString(s.signature(reportID, action, channelID, expires))
+
+	if !hmac.Equal(given, expected) {
+		return &InvalidTokenErr{Reason: "signature mismatch"}
+	}
+
+	if time.Now().After(time.Unix(expiresUnix, 0)) {
+		return &InvalidTokenErr{Reason: "token expired"}
+	}
+
+	return nil
+}
+
+func (s *Signer) signature(reportID, action, channelID, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(reportID + "|" + action + "|" + channelID + "|" + expires))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,67 @@
+package actiontoken
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignerVerify(t *testing.T) {
+	signer := NewSigner("secret", time.Hour)
+	token, expires := signer.Sign("report", "submit", "channel")
+	later := strconv.FormatInt(time.Now().Add(2*time.Hour).Unix(), 10)
+	expiredToken, expired := NewSigner("secret", -time.Minute).Sign("report", "submit", "channel")
+	foreignToken, _ := NewSigner("other secret", time.Hour).Sign("report", "submit", "channel")
+
+	tests := []struct {
+		name      string
+		reportID  string
+		action    string
+		channelID string
+		expires   string
+		token     string
+		valid     bool
+	}{
+		{name: "valid", reportID: "report", action: "submit", channelID: "channel", expires: expires, token: token, valid: true},
+		{name: "other report", reportID: "other", action: "submit", channelID: "channel", expires: expires, token: token},
+		{name: "other action", reportID: "report", action: "mute", channelID: "channel", expires: expires, token: token},
+		{name: "other channel", reportID: "report", action: "submit", channelID: "other", expires: expires, token: token},
+		{name: "extended expiry", reportID: "report", action: "submit", channelID: "channel", expires: later, token: token},
+		{name: "invalid expiry", reportID: "report", action: "submit", channelID: "channel", expires: "tomorrow", token: token},
+		{name: "malformed token", reportID: "report", action: "submit", channelID: "channel", expires: expires, token: "not hex"},
+		{name: "other secret", reportID: "report", action: "submit", channelID: "channel", expires: expires, token: foreignToken},
+		{name: "expired", reportID: "report", action: "submit", channelID: "channel", expires: expired, token: expiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := signer.Verify(tt.reportID, tt.action, tt.channelID, tt.expires, tt.token)
+
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("expected a valid token, got %v", err)
+				}
+
+				return
+			}
+
+			invalid := &InvalidTokenErr{}
+
+			if !errors.As(err, &invalid) {
+				t.Fatalf("expected InvalidTokenErr, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSignerVerifyReportsExpiry(t *testing.T) {
+	signer := NewSigner("secret", -time.Minute)
+	token, expires := signer.Sign("report", "submit", "channel")
+	err := signer.Verify("report", "submit", "channel", expires, token)
+	invalid := &InvalidTokenErr{}
+
+	if !errors.As(err, &invalid) || invalid.Reason != "token expired" {
+		t.Fatalf("expected an expired token, got %v", err)
+	}
+}
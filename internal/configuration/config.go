@@ -9,16 +9,34 @@ import (
 
 type Configuration struct {
 	configType          ConfigType
-	MattermostHost      string   `json:"mattermost_host"`
-	ClientToken         string   `json:"client_token"`
-	BotUsername         string   `json:"bot_username"`
-	BotPassword         string   `json:"bot_password"`
-	BotWantedUsername   string   `json:"bot_wanted_username"`
-	MaintainerUsernames []string `json:"maintainer_usernames"`
-	DevOpsChannel       string   `json:"dev_ops_channel"`
-	TeamID              string   `json:"team_id"`
-	WarnOnEventReasons  []string `json:"warn_on_event_reasons"`
-	WarnOnReachCount    int      `json:"warn_on_reach_count"`
+	DryRun              bool                 `json:"-"` // Set by the dry-run flag
+	MattermostHost      string               `json:"mattermost_host"`
+	ClientToken         string               `json:"client_token"` // Access token of the bot account or a personal access token
+	MaintainerUsernames []string             `json:"maintainer_usernames"`
+	MaintainerEmails    []string             `json:"maintainer_emails"`
+	Smtp                SmtpConfig           `json:"smtp"`
+	DevOpsChannel       string               `json:"dev_ops_channel"`
+	TeamID              string               `json:"team_id"`
+	WarnOnEventReasons  []string             `json:"warn_on_event_reasons"`
+	WarnOnReachCount    int                  `json:"warn_on_reach_count"`
+	SlashCommandToken   string               `json:"slash_command_token"`
+	PublicURL           string               `json:"public_url"`         // Base url of the bot, that mattermost uses for post actions
+	ActionSecret        string               `json:"action_secret"`      // Secret to sign the post actions with
+	FollowUpInterval    int                  `json:"follow_up_interval"` // Minimum minutes between two follow-ups of a report
+	Notifiers           []NotifierConfig     `json:"notifiers"`
+	Routes              []RouteConfig        `json:"routes"`
+	AlertmanagerToken   string               `json:"alertmanager_token"` // Bearer token of the alertmanager webhook receiver
+	RateLimit           int                  `json:"rate_limit"`         // Maximum notifications per minute and notifier
+	StormThreshold      int                  `json:"storm_threshold"`    // New reports per minute, that start an alert storm, 0 disables the detection
+	ClusterName         string               `json:"cluster_name"`       // Name of the cluster, that silences can refer to
+	Silences            []SilenceConfig      `json:"silences"`
+	ApiToken            string               `json:"api_token"`        // Bearer token of the rest api
+	ListenAddress       string               `json:"listen_address"`   // Address of the http server, default is :9090
+	ShutdownTimeout     int                  `json:"shutdown_timeout"` // Seconds to finish requests and deliver notifications on shutdown
+	Storage             StorageConfig        `json:"storage"`
+	LeaderElection      LeaderElectionConfig `json:"leader_election"`
+	LogLevel            string               `json:"log_level"`  // debug, info, warn or error, default is info
+	LogFormat           string               `json:"log_format"` // text or json, default is text
 }
 
 // NewConfiguration is used, to create a new configuration
@@ -29,6 +47,7 @@ type Configuration struct {
 func NewConfiguration() *Configuration {
 	typeValFlag := flag.String("config-type", "", "From where the config should be loaded.")
 	filePathFlag := flag.String("config-path", "", "The path to the config, when a file should be used.")
+	dryRunFlag := flag.Bool("dry-run", false, "Print the notifications instead of sending them and keep the reports in memory.")
 
 	flag.Parse()
 
@@ -40,17 +59,23 @@ func NewConfiguration() *Configuration {
 
 	configType := ParseType(typeVal)
 
+	var config *Configuration
+
 	if configType == FromEnvVars {
-		return newConfigurationFromEnv()
+		config = newConfigurationFromEnv()
 	} else if configType == FromFile {
 		if *filePathFlag == "" {
 			panic("When you want to use a file, you need to add the path! Use -help for more information.")
 		}
 
-		return newConfigurationFromFile(*filePathFlag)
+		config = newConfigurationFromFile(*filePathFlag)
 	}
 
-	return nil
+	if config != nil {
+		config.DryRun = *dryRunFlag
+	}
+
+	return config
 }
 
 // newConfigurationFromFile is used to create a new config
@@ -0,0 +1,12 @@
+package configuration
+
+// LeaderElectionConfig enables the leader election, which is needed
+// to run more than one replica of the bot. The replicas compete for
+// the Lease with the given name and namespace, only the leader
+// watches the cluster and notifies about reports.
+type LeaderElectionConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Namespace string `json:"namespace"`
+	LeaseName string `json:"lease_name"` // Default is k8sbot-leader
+	Identity  string `json:"identity"`   // Default is the hostname, which is the name of the pod
+}
@@ -0,0 +1,20 @@
+package configuration
+
+// NotifierConfig configures an additional sink for reports.
+// The type can be slack, teams, webhook or alertmanager.
+type NotifierConfig struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`    // Only used by the webhook and alertmanager type
+	RateLimit int               `json:"rate_limit"` // Notifications per minute, the global rate_limit is used when not set
+}
+
+// RouteConfig sends every report, which matches the namespaces
+// and reasons, to the notifier with the given name. Empty lists
+// match everything. Mattermost is always available as "mattermost".
+type RouteConfig struct {
+	Namespaces []string `json:"namespaces"`
+	Reasons    []string `json:"reasons"`
+	Notifier   string   `json:"notifier"`
+}
@@ -0,0 +1,16 @@
+package configuration
+
+import "time"
+
+// SilenceConfig configures a maintenance window, in which no
+// new reports are created for matching objects. Empty fields
+// match everything, the object is a regular expression.
+type SilenceConfig struct {
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Reason    string    `json:"reason"`
+	Object    string    `json:"object"`
+	Start     time.Time `json:"start"` // RFC 3339, e.g. 2021-11-20T22:00:00+01:00
+	End       time.Time `json:"end"`
+	Comment   string    `json:"comment"`
+}
@@ -0,0 +1,13 @@
+package configuration
+
+// SmtpConfig configures the email notifier. When no host is
+// set, no emails will be sent. The digest can be empty to send
+// every email immediately, "hourly" or "daily".
+type SmtpConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	Digest   string `json:"digest"`
+}
@@ -0,0 +1,13 @@
+package configuration
+
+// StorageConfig selects the storage of the reports. The type can be
+// "memory", which is the default, or "configmap", which keeps the
+// reports in the ConfigMap with the given name and namespace, so
+// they survive restarts and are shared between replicas. Submitted
+// reports are removed from the ConfigMap after the retention.
+type StorageConfig struct {
+	Type          string `json:"type"`
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`           // Default is k8sbot-reports
+	RetentionDays int    `json:"retention_days"` // Default is 7
+}
@@ -0,0 +1,84 @@
+package election
+
+import (
+	"context"
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8sbot/internal/k8s"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/metrics"
+	"log/slog"
+	"time"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// Elector elects one replica of the bot as leader with a Lease.
+// The leader releases the Lease on shutdown, so another
+// replica takes over without waiting for the lease duration.
+type Elector struct {
+	lock     *resourcelock.LeaseLock
+	identity string
+	logger   *slog.Logger
+}
+
+func NewElector(api *k8s.KubernetesApi, namespace, name, identity string, logger *slog.Logger) *Elector {
+	return &Elector{
+		lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Client:     api.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		identity: identity,
+		logger:   logger.With(logging.Component, "election", "identity", identity),
+	}
+}
+
+// Run competes for the leadership until the context is done. Each
+// time this replica becomes the leader, lead is called with a
+// context, which is canceled when the leadership is lost.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) error {
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            e.lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            e.lock.LeaseMeta.Name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				e.logger.Info("started leading")
+				metrics.Leader.Set(1)
+				lead(ctx)
+			},
+			OnStoppedLeading: func() {
+				e.logger.Info("stopped leading")
+				metrics.Leader.Set(0)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != e.identity {
+					e.logger.Info("following leader", "leader", identity)
+				}
+			},
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("cannot create leader elector: %w", err)
+	}
+
+	for ctx.Err() == nil {
+		elector.Run(ctx)
+	}
+
+	return nil
+}
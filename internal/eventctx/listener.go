@@ -3,76 +3,142 @@ package eventctx
 import (
 	"context"
 	"fmt"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8sbot/internal/k8s"
-	"k8sbot/internal/mattermost"
+	"k8sbot/internal/listener"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/metrics"
+	"k8sbot/internal/reporter"
+	"log/slog"
 	"time"
 )
 
+const (
+	interval = 5 * time.Second
+
+	// warningType is the type of the events, which can be reported.
+	warningType = "Warning"
+)
+
 type EventListener struct {
-	mattermost         *mattermost.MattermostHandler
+	reporter           *reporter.Reporter
 	api                *k8s.KubernetesApi
 	warnOnEventReasons []string
 	count              int
+	// seenCounts is the count of every warning event at the
+	// last cycle, so only new occurrences are counted.
+	seenCounts map[types.UID]int32
+	health     *listener.Health
+	logger     *slog.Logger
 }
 
-func NewEventListener(handler *mattermost.MattermostHandler, api *k8s.KubernetesApi, warnOnEventReasons []string, count int) *EventListener {
+func NewEventListener(reporter *reporter.Reporter, api *k8s.KubernetesApi, warnOnEventReasons []string, count int, logger *slog.Logger) *EventListener {
 	return &EventListener{
 		api:                api,
-		mattermost:         handler,
+		reporter:           reporter,
 		warnOnEventReasons: warnOnEventReasons,
 		count:              count,
+		seenCounts:         map[types.UID]int32{},
+		health:             listener.NewHealth(interval),
+		logger:             logger.With(logging.Component, "eventctx"),
 	}
 }
 
-func (e *EventListener) check() error {
-	namespaceList, err := e.api.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{})
+func (e *EventListener) check(ctx context.Context) error {
+	defer metrics.ObserveCycle(e.Name(), time.Now())
+
+	seen := map[types.UID]bool{}
+
+	namespaceList, err := e.api.CoreV1().Namespaces().List(ctx, v1.ListOptions{})
 
 	if err != nil {
 		return fmt.Errorf("cannot get namespaces: %w", err)
 	}
 
 	for _, namespace := range namespaceList.Items {
-		eventList, err := e.api.CoreV1().Events(namespace.GetName()).List(context.Background(), v1.ListOptions{})
+		eventList, err := e.api.CoreV1().Events(namespace.GetName()).List(ctx, v1.ListOptions{})
 
 		if err != nil {
 			return fmt.Errorf("cannot get event-list for namespace %v: %w", namespace.GetName(), err)
 		}
 
-		for _, event := range eventList.Items {
-			if event.Type == "Warning" {
-				for _, reason := range e.warnOnEventReasons {
-					if reason == event.Reason {
-						if event.Count >= int32(e.count) {
-							if err := e.mattermost.SendReport(event.ObjectMeta.UID, event.Namespace, event.Reason, event.ObjectMeta.Name, event.Message, event.LastTimestamp.String(), event.Count); err != nil {
-								return fmt.Errorf("cannot send new report: %w", err)
-							}
-						}
-					}
-				}
+		for i := range eventList.Items {
+			event := &eventList.Items[i]
+
+			if event.Type == warningType {
+				seen[event.UID] = true
 			}
+
+			if err := e.Process(event); err != nil {
+				return err
+			}
+		}
+	}
+
+	for uid := range e.seenCounts {
+		if !seen[uid] {
+			delete(e.seenCounts, uid)
 		}
 	}
 
+	e.logger.Debug("checked events", "namespaces", len(namespaceList.Items), "warnings", len(seen))
+
 	return nil
 }
 
-func (e *EventListener) Listen(done <-chan bool) error {
-	ticker := time.NewTicker(5 * time.Second)
-
-	go func() {
-		for {
-			select {
-			case <-done:
-				ticker.Stop()
-				return
-			case _ = <-ticker.C:
-				if err := e.check(); err != nil {
-					e.mattermost.SendInternalError(err)
+// Process counts the new occurrences of a warning event and reports
+// it, when its reason is watched and it occurred often enough. Other
+// events are ignored.
+func (e *EventListener) Process(event *corev1.Event) error {
+	if event.Type != warningType {
+		return nil
+	}
+
+	if event.Count > e.seenCounts[event.UID] {
+		metrics.EventsProcessed.WithLabelValues(event.Namespace, event.Reason).Add(float64(event.Count - e.seenCounts[event.UID]))
+	}
+
+	e.seenCounts[event.UID] = event.Count
+
+	for _, reason := range e.warnOnEventReasons {
+		if reason == event.Reason {
+			if event.Count >= int32(e.count) {
+				if err := e.reporter.SendReport(event.ObjectMeta.UID, event.Namespace, event.Reason, event.ObjectMeta.Name, event.Message, event.Count); err != nil {
+					return fmt.Errorf("cannot send new report: %w", err)
 				}
 			}
 		}
-	}()
+	}
 
 	return nil
 }
+
+func (e *EventListener) Name() string {
+	return "eventctx"
+}
+
+func (e *EventListener) Health() *listener.Health {
+	return e.health
+}
+
+// Listen checks the cluster once per interval until the context
+// is done. It returns the error of the first failed cycle.
+func (e *EventListener) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _ = <-ticker.C:
+			if err := e.check(ctx); err != nil {
+				return err
+			}
+
+			e.health.Record(nil)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"k8s.io/apimachinery/pkg/types"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reporter"
+	"log/slog"
+	"net/http"
+)
+
+// alertmanagerResolver is used as the name of the
+// user, who submits reports of resolved alerts.
+const alertmanagerResolver = "Alertmanager"
+
+type alertmanagerAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+type alertmanagerMessage struct {
+	Version string               `json:"version"`
+	Status  string               `json:"status"`
+	Alerts  []*alertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerEndpoints receives the notifications of the webhook
+// receiver of a prometheus alertmanager and reports them like
+// kubernetes events. Resolved alerts submit their reports.
+type AlertmanagerEndpoints struct {
+	reporter *reporter.Reporter
+	token    string
+	logger   *slog.Logger
+}
+
+func NewAlertmanagerEndpoints(serveMux *http.ServeMux, reporter *reporter.Reporter, token string, logger *slog.Logger) *AlertmanagerEndpoints {
+	a := &AlertmanagerEndpoints{
+		reporter: reporter,
+		token:    token,
+		logger:   logger.With(logging.Component, "alertmanager"),
+	}
+
+	serveMux.HandleFunc("/alertmanager/webhook", a.handleWebhook)
+
+	return a
+}
+
+func (a *AlertmanagerEndpoints) handleWebhook(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !a.verifyToken(request.Header.Get("Authorization")) {
+		http.Error(writer, "unauthorized", http.StatusUnauthorized)
+		a.logger.Warn("rejected alertmanager webhook with invalid token", logging.Remote, request.RemoteAddr)
+		return
+	}
+
+	message := &alertmanagerMessage{}
+
+	if err := json.NewDecoder(request.Body).Decode(message); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		a.logger.Warn("cannot parse alertmanager message", logging.Err(err))
+		return
+	}
+
+	for _, alert := range message.Alerts {
+		if err := a.handleAlert(alert); err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			a.logger.Error("cannot handle alert", logging.Err(err))
+			return
+		}
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// verifyToken checks the bearer token of the authorization
+// header. When no token is configured, every request will
+// be rejected.
+func (a *AlertmanagerEndpoints) verifyToken(header string) bool {
+	return verifyBearerToken(a.token, header)
+}
+
+func (a *AlertmanagerEndpoints) handleAlert(alert *alertmanagerAlert) error {
+	if alert.Fingerprint == "" {
+		return fmt.Errorf("alert without fingerprint")
+	}
+
+	objectID := types.UID("alertmanager-" + alert.Fingerprint)
+
+	if alert.Status == "resolved" {
+		return a.reporter.ResolveObject(objectID, alertmanagerResolver)
+	}
+
+	message := alert.Annotations["description"]
+
+	if message == "" {
+		message = alert.Annotations["summary"]
+	}
+
+	return a.reporter.SendAlert(objectID, alert.Labels["namespace"], alert.Labels["alertname"], alertResource(alert.Labels), message)
+}
+
+// alertResource returns the most specific kubernetes
+// object, that is named by the labels of the alert.
+func alertResource(labels map[string]string) string {
+	for _, key := range []string{"pod", "deployment", "statefulset", "daemonset", "job", "persistentvolumeclaim", "service", "node", "instance"} {
+		if val, ok := labels[key]; ok && val != "" {
+			return val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"k8sbot/internal/logging"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+//go:embed openapi.json
+var openApiDocument []byte
+
+// NewApiRouter registers the rest api under /api/v1 and returns
+// the router, the api endpoints are added to. Every request needs
+// the token as bearer token. When no token is configured, every
+// request will be rejected. Only the OpenAPI document at
+// /api/v1/openapi.json is public.
+func NewApiRouter(serveMux *http.ServeMux, token string, logger *slog.Logger) *mux.Router {
+	logger = logger.With(logging.Component, "api")
+
+	root := mux.NewRouter()
+
+	root.HandleFunc("/api/v1/openapi.json", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+
+		if _, err := writer.Write(openApiDocument); err != nil {
+			logger.Error("cannot write openapi document", logging.Err(err))
+		}
+	}).Methods(http.MethodGet)
+
+	api := root.PathPrefix("/api/v1").Subrouter()
+
+	api.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if !verifyBearerToken(token, request.Header.Get("Authorization")) {
+				http.Error(writer, "unauthorized", http.StatusUnauthorized)
+				logger.Warn("rejected api request with invalid token", logging.Remote, request.RemoteAddr)
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	})
+
+	serveMux.Handle("/api/", root)
+
+	return api
+}
+
+func verifyBearerToken(token, header string) bool {
+	if token == "" || !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(strings.TrimPrefix(header, "Bearer "))) == 1
+}
+
+func writeJSON(logger *slog.Logger, writer http.ResponseWriter, status int, val interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	if err := json.NewEncoder(writer).Encode(val); err != nil {
+		logger.Error("cannot write api response", logging.Err(err))
+	}
+}
@@ -0,0 +1,404 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golangee/uuid"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reporter"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const timeFormat = "15:04:05 02.01.2006"
+
+// CommandEndpoints serves the /k8sbot slash-command
+// of mattermost. Every response is ephemeral, so only
+// the user who used the command can see it.
+type CommandEndpoints struct {
+	reporter *reporter.Reporter
+	storage  reportstorage.ReportStorage
+	token    string
+	res      i18n.Resources
+	logger   *slog.Logger
+}
+
+func NewCommandEndpoints(serveMux *http.ServeMux, reporter *reporter.Reporter, storage reportstorage.ReportStorage, token string, logger *slog.Logger) *CommandEndpoints {
+	c := &CommandEndpoints{
+		reporter: reporter,
+		storage:  storage,
+		token:    token,
+		res:      i18n.NewResources("de-DE"),
+		logger:   logger.With(logging.Component, "commands"),
+	}
+
+	serveMux.HandleFunc("/command", c.handleCommand)
+
+	return c
+}
+
+func (c *CommandEndpoints) handleCommand(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		c.logger.Warn("cannot parse form from command", logging.Err(err))
+		return
+	}
+
+	if !c.verifyToken(request.FormValue("token")) {
+		http.Error(writer, "invalid token", http.StatusUnauthorized)
+		c.logger.Warn("rejected command with invalid token", logging.Remote, request.RemoteAddr)
+		return
+	}
+
+	text, err := c.execute(strings.Fields(request.FormValue("text")), request.FormValue("user_name"))
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		c.logger.Error("cannot execute command", logging.Err(err))
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(writer).Encode(&model.CommandResponse{
+		ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL,
+		Text:         text,
+	}); err != nil {
+		c.logger.Error("cannot write command response", logging.Err(err))
+	}
+}
+
+// verifyToken checks the token which was sent by mattermost
+// against the configured one. When no token is configured,
+// every command will be rejected.
+func (c *CommandEndpoints) verifyToken(token string) bool {
+	if c.token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(c.token), []byte(token)) == 1
+}
+
+// execute runs the given sub-command and returns the
+// text, that should be shown to the user. Errors are
+// only returned, when something unexpected went wrong.
+func (c *CommandEndpoints) execute(args []string, username string) (string, error) {
+	if len(args) == 0 {
+		return c.res.CommandUsage(), nil
+	}
+
+	switch args[0] {
+	case "list":
+		namespace := ""
+
+		if len(args) > 1 {
+			namespace = args[1]
+		}
+
+		return c.list(namespace)
+	case "show":
+		if len(args) != 2 {
+			return c.res.CommandUsage(), nil
+		}
+
+		return c.show(args[1])
+	case "ack":
+		if len(args) != 2 {
+			return c.res.CommandUsage(), nil
+		}
+
+		return c.ack(args[1], username)
+	case "mute":
+		if len(args) != 3 {
+			return c.res.CommandUsage(), nil
+		}
+
+		return c.mute(args[1], args[2], username)
+	case "silence":
+		if len(args) != 4 && len(args) != 5 {
+			return c.res.CommandUsage(), nil
+		}
+
+		object := ""
+
+		if len(args) == 5 {
+			object = args[4]
+		}
+
+		return c.silence(args[1], args[2], args[3], object, username)
+	case "unsilence":
+		if len(args) != 2 {
+			return c.res.CommandUsage(), nil
+		}
+
+		return c.unsilence(args[1])
+	case "status":
+		return c.status()
+	default:
+		return c.res.CommandUsage(), nil
+	}
+}
+
+func (c *CommandEndpoints) list(namespace string) (string, error) {
+	reports, err := c.storage.ReadAll()
+
+	if err != nil {
+		return "", fmt.Errorf("cannot read reports: %w", err)
+	}
+
+	builder := &strings.Builder{}
+
+	for _, r := range reports {
+		if r.ReportStopped || (namespace != "" && r.Namespace != namespace) {
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("| %v | %v | %v | %v | %v |\n", r.ID.String(), r.Namespace, r.Reason, r.Resource, r.Count))
+	}
+
+	if builder.Len() == 0 {
+		return c.res.NoOpenReports(), nil
+	}
+
+	header := fmt.Sprintf("#### %v\n| %v | %v | %v | %v | %v |\n|---|---|---|---|---|\n", c.res.OpenReports(), c.res.Id(), c.res.Namespace(), c.res.Reason(), c.res.Object(), c.res.Count())
+
+	return header + builder.String(), nil
+}
+
+func (c *CommandEndpoints) show(idStr string) (string, error) {
+	report, text, err := c.readReport(idStr)
+
+	if report == nil {
+		return text, err
+	}
+
+	state := c.res.StateOpen()
+
+	if report.ReportStopped {
+		state = fmt.Sprintf("%v (%v)", c.res.StateSubmitted(), report.ReportStoppedBy)
+	}
+
+	builder := &strings.Builder{}
+
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n|---|---|\n", c.res.Id(), report.ID.String()))
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.Namespace(), report.Namespace))
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.Reason(), report.Reason))
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.Object(), report.Resource))
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.Message(), report.Msg))
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.Count(), report.Count))
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.CountReportFromBot(), report.ReportTimes))
+	builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.State(), state))
+
+	if report.AssignedTo != "" {
+		builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.AssignedTo(), report.AssignedTo))
+	}
+
+	if report.IsSnoozed(time.Now()) {
+		builder.WriteString(fmt.Sprintf("| %v | %v |\n", c.res.SnoozedUntil(), report.SnoozedUntil.Format(timeFormat)))
+	}
+
+	return builder.String(), nil
+}
+
+// ack submits the report. Already submitted reports stay unchanged.
+func (c *CommandEndpoints) ack(idStr, username string) (string, error) {
+	report, text, err := c.readReport(idStr)
+
+	if report == nil {
+		return text, err
+	}
+
+	if report.ReportStopped {
+		return c.res.ReportAlreadySubmitted(report.ReportStoppedBy), nil
+	}
+
+	if err := c.reporter.SubmitReport(report.ID, username); err != nil {
+		return "", fmt.Errorf("cannot submit report: %w", err)
+	}
+
+	return c.res.ReportSubmitted(report.ID.String()), nil
+}
+
+func (c *CommandEndpoints) mute(reason, durationStr, username string) (string, error) {
+	duration, err := time.ParseDuration(durationStr)
+
+	if err != nil || duration <= 0 {
+		return c.res.InvalidDuration(durationStr), nil
+	}
+
+	until := time.Now().Add(duration)
+
+	if err := c.reporter.MuteReason("", reason, until, username); err != nil {
+		return "", fmt.Errorf("cannot mute reason: %w", err)
+	}
+
+	return c.res.ReasonMuted(reason, until.Format(timeFormat)), nil
+}
+
+// silence creates a silence, which starts immediately. A * as
+// namespace or reason matches every namespace or reason.
+func (c *CommandEndpoints) silence(namespace, reason, durationStr, object, username string) (string, error) {
+	duration, err := time.ParseDuration(durationStr)
+
+	if err != nil || duration <= 0 {
+		return c.res.InvalidDuration(durationStr), nil
+	}
+
+	if namespace == "*" {
+		namespace = ""
+	}
+
+	if reason == "*" {
+		reason = ""
+	}
+
+	now := time.Now()
+	silence := &reportstorage.Silence{
+		ID:        uuid.New(),
+		Namespace: namespace,
+		Reason:    reason,
+		Object:    object,
+		Start:     now,
+		End:       now.Add(duration),
+		CreatedBy: username,
+	}
+
+	if err := silence.Validate(); err != nil {
+		return c.res.InvalidSilence(err.Error()), nil
+	}
+
+	if err := c.reporter.AddSilence(silence); err != nil {
+		return "", fmt.Errorf("cannot add silence: %w", err)
+	}
+
+	return c.res.SilenceCreated(silence.ID.String(), silence.End.Format(timeFormat)), nil
+}
+
+func (c *CommandEndpoints) unsilence(idStr string) (string, error) {
+	id, err := uuid.Parse(idStr)
+
+	if err != nil {
+		return c.res.SilenceNotFound(idStr), nil
+	}
+
+	silences, err := c.storage.ReadSilences()
+
+	if err != nil {
+		return "", fmt.Errorf("cannot read silences: %w", err)
+	}
+
+	for _, s := range silences {
+		if s.ID == id {
+			if err := c.reporter.DeleteSilence(id); err != nil {
+				return "", fmt.Errorf("cannot delete silence: %w", err)
+			}
+
+			return c.res.SilenceDeleted(idStr), nil
+		}
+	}
+
+	return c.res.SilenceNotFound(idStr), nil
+}
+
+func (c *CommandEndpoints) status() (string, error) {
+	reports, err := c.storage.ReadAll()
+
+	if err != nil {
+		return "", fmt.Errorf("cannot read reports: %w", err)
+	}
+
+	open, submitted := 0, 0
+
+	for _, r := range reports {
+		if r.ReportStopped {
+			submitted++
+		} else {
+			open++
+		}
+	}
+
+	mutes, err := c.storage.ReadMutes()
+
+	if err != nil {
+		return "", fmt.Errorf("cannot read mutes: %w", err)
+	}
+
+	builder := &strings.Builder{}
+
+	builder.WriteString(c.res.StatusSummary(open, submitted))
+	builder.WriteString("\n\n")
+
+	if len(mutes) == 0 {
+		builder.WriteString(c.res.NoActiveMutes())
+	} else {
+		builder.WriteString(fmt.Sprintf("#### %v\n", c.res.ActiveMutes()))
+
+		for _, m := range mutes {
+			reason := m.Reason
+
+			if m.Namespace != "" {
+				reason = fmt.Sprintf("%v (%v)", m.Reason, m.Namespace)
+			}
+
+			builder.WriteString(fmt.Sprintf("* %v\n", c.res.MuteEntry(reason, m.Until.Format(timeFormat), m.MutedBy)))
+		}
+	}
+
+	silences, err := c.storage.ReadSilences()
+
+	if err != nil {
+		return "", fmt.Errorf("cannot read silences: %w", err)
+	}
+
+	builder.WriteString("\n")
+
+	if len(silences) == 0 {
+		builder.WriteString(c.res.NoSilences())
+	} else {
+		builder.WriteString(fmt.Sprintf("#### %v\n| %v | %v | %v | %v | %v | %v | %v | %v |\n|---|---|---|---|---|---|---|---|\n", c.res.Silences(), c.res.Id(), c.res.Cluster(), c.res.Namespace(), c.res.Reason(), c.res.Object(), c.res.Start(), c.res.End(), c.res.CreatedBy()))
+
+		for _, s := range silences {
+			builder.WriteString(fmt.Sprintf("| %v | %v | %v | %v | %v | %v | %v | %v |\n", s.ID.String(), orAll(s.Cluster), orAll(s.Namespace), orAll(s.Reason), orAll(s.Object), s.Start.Format(timeFormat), s.End.Format(timeFormat), s.CreatedBy))
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// readReport parses the given id and reads the matching report.
+// When the id is invalid or no report was found, the report is
+// nil and the returned text explains the problem to the user.
+func (c *CommandEndpoints) readReport(idStr string) (*reportstorage.Report, string, error) {
+	id, err := uuid.Parse(idStr)
+
+	if err != nil {
+		return nil, c.res.InvalidReportId(idStr), nil
+	}
+
+	report, err := c.storage.ReadByReportID(id)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		return nil, c.res.ReportNotFound(idStr), nil
+	} else if err != nil {
+		return nil, "", fmt.Errorf("cannot read report: %w", err)
+	}
+
+	return report, "", nil
+}
+
+// orAll returns * for empty fields of a silence,
+// because they match everything.
+func orAll(val string) string {
+	if val == "" {
+		return "*"
+	}
+
+	return val
+}
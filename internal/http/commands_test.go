@@ -0,0 +1,196 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/golangee/uuid"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"io"
+	"k8sbot/internal/notifier"
+	"k8sbot/internal/reporter"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// discardNotifier drops every notification.
+type discardNotifier struct{}
+
+func (discardNotifier) SendReport(report *reportstorage.Report) error {
+	return nil
+}
+
+func (discardNotifier) UpdateReport(report *reportstorage.Report, kind notifier.UpdateKind) error {
+	return nil
+}
+
+func (discardNotifier) ResolveReport(report *reportstorage.Report) error {
+	return nil
+}
+
+func (discardNotifier) SendInternalError(err error) {
+}
+
+func newTestCommandEndpoints(storage reportstorage.ReportStorage, token string) (*CommandEndpoints, *http.ServeMux) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	r := reporter.NewReporter(storage, discardNotifier{}, time.Minute, 0, "", logger)
+	serveMux := http.NewServeMux()
+
+	return NewCommandEndpoints(serveMux, r, storage, token, logger), serveMux
+}
+
+func runCommand(t *testing.T, serveMux *http.ServeMux, token, text string) (int, string) {
+	t.Helper()
+
+	form := url.Values{"token": {token}, "text": {text}, "user_name": {"alice"}}
+	request := httptest.NewRequest(http.MethodPost, "/command", strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+
+	serveMux.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, recorder.Body.String()
+	}
+
+	response := &model.CommandResponse{}
+
+	if err := json.NewDecoder(recorder.Body).Decode(response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.ResponseType != model.COMMAND_RESPONSE_TYPE_EPHEMERAL {
+		t.Errorf("expected an ephemeral response, got %v", response.ResponseType)
+	}
+
+	return recorder.Code, response.Text
+}
+
+func TestCommandEndpointsParseCommands(t *testing.T) {
+	storage := reportstorage.NewInMemoryReportStorage()
+	c, serveMux := newTestCommandEndpoints(storage, "token")
+	res := c.res
+	open := &reportstorage.Report{ID: uuid.New(), Namespace: "default", Reason: "BackOff", Resource: "api"}
+	submitted := &reportstorage.Report{ID: uuid.New(), Namespace: "default", Reason: "BackOff", ReportStopped: true, ReportStoppedBy: "bob"}
+	unknown := uuid.New().String()
+
+	for _, r := range []*reportstorage.Report{open, submitted} {
+		if err := storage.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		want     string
+		contains []string
+		excludes []string
+	}{
+		{name: "no sub-command", text: "", want: res.CommandUsage()},
+		{name: "unknown sub-command", text: "restart", want: res.CommandUsage()},
+		{name: "show without id", text: "show", want: res.CommandUsage()},
+		{name: "show with invalid id", text: "show 42", want: res.InvalidReportId("42")},
+		{name: "show unknown report", text: "show " + unknown, want: res.ReportNotFound(unknown)},
+		{name: "show report", text: "show " + open.ID.String(), contains: []string{open.ID.String(), "api", res.StateOpen()}},
+		{name: "list", text: "list", contains: []string{open.ID.String()}, excludes: []string{submitted.ID.String()}},
+		{name: "list namespace", text: "list  kube-system", want: res.NoOpenReports()},
+		{name: "ack submitted report", text: "ack " + submitted.ID.String(), want: res.ReportAlreadySubmitted("bob")},
+		{name: "ack with extra arguments", text: "ack " + open.ID.String() + " now", want: res.CommandUsage()},
+		{name: "mute without duration", text: "mute BackOff", want: res.CommandUsage()},
+		{name: "mute with invalid duration", text: "mute BackOff soon", want: res.InvalidDuration("soon")},
+		{name: "mute with negative duration", text: "mute BackOff -1h", want: res.InvalidDuration("-1h")},
+		{name: "silence with invalid object", text: "silence * * 1h (", contains: []string{res.InvalidSilence("")}},
+		{name: "unsilence unknown silence", text: "unsilence " + unknown, want: res.SilenceNotFound(unknown)},
+		{name: "status", text: "status", contains: []string{res.StatusSummary(1, 1), res.NoActiveMutes(), res.NoSilences()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, text := runCommand(t, serveMux, "token", tt.text)
+
+			if code != http.StatusOK {
+				t.Fatalf("expected status 200, got %v: %v", code, text)
+			}
+
+			if tt.want != "" && text != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, text)
+			}
+
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("expected %q in %q", s, text)
+				}
+			}
+
+			for _, s := range tt.excludes {
+				if strings.Contains(text, s) {
+					t.Errorf("expected no %q in %q", s, text)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandEndpointsChangeState(t *testing.T) {
+	storage := reportstorage.NewInMemoryReportStorage()
+	c, serveMux := newTestCommandEndpoints(storage, "token")
+	report := &reportstorage.Report{ID: uuid.New(), Namespace: "default", Reason: "BackOff"}
+
+	if err := storage.Write(report); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, text := runCommand(t, serveMux, "token", "ack "+report.ID.String()); text != c.res.ReportSubmitted(report.ID.String()) {
+		t.Fatalf("expected the report to be submitted, got %q", text)
+	}
+
+	if stored, _ := storage.ReadByReportID(report.ID); !stored.ReportStopped || stored.ReportStoppedBy != "alice" {
+		t.Fatalf("expected the report to be submitted by alice, got %+v", stored)
+	}
+
+	runCommand(t, serveMux, "token", "mute BackOff 1h")
+
+	if mute, _ := reportstorage.FindMute(storage, time.Now(), "other", "BackOff"); mute == nil || mute.MutedBy != "alice" {
+		t.Fatalf("expected the reason to be muted in all namespaces, got %+v", mute)
+	}
+
+	runCommand(t, serveMux, "token", "silence * FailedMount 1h api-.*")
+	silences, _ := storage.ReadSilences()
+
+	if len(silences) != 1 || silences[0].Namespace != "" || silences[0].Reason != "FailedMount" || silences[0].Object != "api-.*" {
+		t.Fatalf("expected a silence of FailedMount in all namespaces, got %+v", silences)
+	}
+
+	if _, text := runCommand(t, serveMux, "token", "unsilence "+silences[0].ID.String()); text != c.res.SilenceDeleted(silences[0].ID.String()) {
+		t.Fatalf("expected the silence to be deleted, got %q", text)
+	}
+}
+
+func TestCommandEndpointsVerifyToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		sent       string
+		code       int
+	}{
+		{name: "valid token", configured: "token", sent: "token", code: http.StatusOK},
+		{name: "invalid token", configured: "token", sent: "guess", code: http.StatusUnauthorized},
+		{name: "missing token", configured: "token", sent: "", code: http.StatusUnauthorized},
+		{name: "no configured token", configured: "", sent: "", code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, serveMux := newTestCommandEndpoints(reportstorage.NewInMemoryReportStorage(), tt.configured)
+
+			if code, _ := runCommand(t, serveMux, tt.sent, "status"); code != tt.code {
+				t.Fatalf("expected status %v, got %v", tt.code, code)
+			}
+		})
+	}
+}
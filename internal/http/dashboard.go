@@ -0,0 +1,182 @@
+package http
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dashboardDays is the number of days, the
+// counts per namespace and reason are shown for.
+const dashboardDays = 7
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(dashboardHTML))
+
+type dashboardReport struct {
+	Report      *reportstorage.Report
+	State       string
+	CreatedAt   string
+	SubmittedAt string
+	PostURL     string
+}
+
+// dashboardCount is the number of created reports
+// of a reason in a namespace per day.
+type dashboardCount struct {
+	Namespace string
+	Reason    string
+	PerDay    []int
+}
+
+// DashboardEndpoints serves a read-only overview of the reports
+// at /dashboard. The page uses basic auth, the password is the
+// token of the api, the username is ignored.
+type DashboardEndpoints struct {
+	storage reportstorage.ReportStorage
+	token   string
+	postURL string
+	res     i18n.Resources
+	logger  *slog.Logger
+}
+
+// NewDashboardEndpoints creates the dashboard. The host and team
+// name of mattermost are used to link the posts of the reports.
+func NewDashboardEndpoints(serveMux *http.ServeMux, storage reportstorage.ReportStorage, token, mattermostHost, teamName string, logger *slog.Logger) *DashboardEndpoints {
+	d := &DashboardEndpoints{
+		storage: storage,
+		token:   token,
+		postURL: fmt.Sprintf("%v/%v/pl/", strings.TrimSuffix(mattermostHost, "/"), teamName),
+		res:     i18n.NewResources("de-DE"),
+		logger:  logger.With(logging.Component, "dashboard"),
+	}
+
+	serveMux.HandleFunc("/dashboard", d.handleDashboard)
+
+	return d
+}
+
+func (d *DashboardEndpoints) handleDashboard(writer http.ResponseWriter, request *http.Request) {
+	if _, password, _ := request.BasicAuth(); !verifyBearerToken(d.token, "Bearer "+password) {
+		writer.Header().Set("WWW-Authenticate", `Basic realm="k8sbot"`)
+		http.Error(writer, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	reports, err := d.storage.ReadAll()
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		d.logger.Error("cannot read reports", logging.Err(err))
+		return
+	}
+
+	open, submitted := []*dashboardReport{}, []*dashboardReport{}
+
+	for _, r := range reports {
+		report := d.newDashboardReport(r)
+
+		if r.ReportStopped {
+			submitted = append(submitted, report)
+		} else {
+			open = append(open, report)
+		}
+	}
+
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].Report.CreatedAt.After(open[j].Report.CreatedAt)
+	})
+
+	sort.Slice(submitted, func(i, j int) bool {
+		return submitted[i].Report.ReportStoppedAt.After(submitted[j].Report.ReportStoppedAt)
+	})
+
+	days, counts := countPerDay(reports, time.Now())
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := dashboardTemplate.Execute(writer, map[string]interface{}{
+		"Res":       d.res,
+		"Open":      open,
+		"Submitted": submitted,
+		"Days":      days,
+		"Counts":    counts,
+	}); err != nil {
+		d.logger.Error("cannot render dashboard", logging.Err(err))
+	}
+}
+
+func (d *DashboardEndpoints) newDashboardReport(report *reportstorage.Report) *dashboardReport {
+	r := &dashboardReport{
+		Report:    report,
+		State:     newReportResponse(report).State,
+		CreatedAt: report.CreatedAt.Format(timeFormat),
+	}
+
+	if report.ReportStopped {
+		r.SubmittedAt = report.ReportStoppedAt.Format(timeFormat)
+	}
+
+	if report.PostID != "" {
+		r.PostURL = d.postURL + report.PostID
+	}
+
+	return r
+}
+
+// countPerDay counts the reports, which were created in the last
+// days, by namespace and reason. The first day is the oldest one.
+func countPerDay(reports []*reportstorage.Report, now time.Time) ([]string, []*dashboardCount) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := today.AddDate(0, 0, -(dashboardDays - 1))
+
+	days := []string{}
+
+	for i := 0; i < dashboardDays; i++ {
+		days = append(days, first.AddDate(0, 0, i).Format("02.01."))
+	}
+
+	byKey := map[string]*dashboardCount{}
+	counts := []*dashboardCount{}
+
+	for _, r := range reports {
+		if r.CreatedAt.Before(first) {
+			continue
+		}
+
+		key := r.Namespace + "/" + r.Reason
+
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = &dashboardCount{Namespace: r.Namespace, Reason: r.Reason, PerDay: make([]int, dashboardDays)}
+			counts = append(counts, byKey[key])
+		}
+
+		day := dashboardDays - 1
+
+		for day > 0 && r.CreatedAt.Before(first.AddDate(0, 0, day)) {
+			day--
+		}
+
+		byKey[key].PerDay[day]++
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Namespace != counts[j].Namespace {
+			return counts[i].Namespace < counts[j].Namespace
+		}
+
+		return counts[i].Reason < counts[j].Reason
+	})
+
+	return days, counts
+}
@@ -1,49 +1,141 @@
 package http
 
 import (
-	"github.com/golangee/uuid"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"k8sbot/internal/actiontoken"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
 	"k8sbot/internal/mattermost"
-	"log"
+	"k8sbot/internal/reporter"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
+// muteDuration is the time, a reason stays muted
+// in a namespace after the mute button was clicked.
+const muteDuration = 24 * time.Hour
+
 type ReportEndpoints struct {
-	handler *mattermost.MattermostHandler
+	handler  *mattermost.MattermostHandler
+	reporter *reporter.Reporter
+	storage  reportstorage.ReportStorage
+	res      i18n.Resources
+	logger   *slog.Logger
 }
 
-func NewReportEndpoints(handler *mattermost.MattermostHandler) *ReportEndpoints {
+func NewReportEndpoints(serveMux *http.ServeMux, handler *mattermost.MattermostHandler, reporter *reporter.Reporter, storage reportstorage.ReportStorage, logger *slog.Logger) *ReportEndpoints {
 	r := &ReportEndpoints{
-		handler: handler,
+		handler:  handler,
+		reporter: reporter,
+		storage:  storage,
+		res:      i18n.NewResources("de-DE"),
+		logger:   logger.With(logging.Component, "actions"),
 	}
 
-	http.HandleFunc("/report/submit", r.handleSubmit)
+	serveMux.HandleFunc("/report/action", r.handleAction)
 
 	return r
 }
 
-func (r *ReportEndpoints) handleSubmit(writer http.ResponseWriter, request *http.Request) {
-	if err := request.ParseForm(); err != nil {
+// handleAction receives the integration request, that mattermost
+// sends when a user clicks on a button of a report.
+func (r *ReportEndpoints) handleAction(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actionRequest := &model.PostActionIntegrationRequest{}
+
+	if err := json.NewDecoder(request.Body).Decode(actionRequest); err != nil {
 		http.Error(writer, err.Error(), http.StatusBadRequest)
-		log.Println("cannot parse form from user: ", err.Error())
+		r.logger.Warn("cannot parse action request", logging.Err(err))
 		return
 	}
 
-	idStr := request.FormValue("reportID")
+	action, err := r.handler.ParseAction(actionRequest)
 
-	id, err := uuid.Parse(idStr)
+	var invalidTokenErr *actiontoken.InvalidTokenErr
+	var unauthorizedUserErr *mattermost.UnauthorizedUserErr
 
-	if err != nil {
+	if errors.As(err, &invalidTokenErr) {
+		http.Error(writer, "unauthorized", http.StatusUnauthorized)
+		r.logger.Warn("rejected action", logging.Remote, request.RemoteAddr, logging.Err(err))
+		return
+	} else if errors.As(err, &unauthorizedUserErr) {
+		http.Error(writer, "forbidden", http.StatusForbidden)
+		r.logger.Warn("rejected action", logging.User, unauthorizedUserErr.Username, logging.Err(err))
+		return
+	} else if err != nil {
 		http.Error(writer, err.Error(), http.StatusBadRequest)
-		log.Println("cannot parse given id: ", err)
+		r.logger.Warn("cannot parse action", logging.Err(err))
+		return
 	}
 
-	username := request.FormValue("user")
+	response, err := r.executeAction(action, actionRequest.PostId)
 
-	if err := r.handler.SubmitReport(id, username); err != nil {
+	if err != nil {
 		http.Error(writer, err.Error(), http.StatusInternalServerError)
-		log.Println("cannot submit report: ", err.Error())
+		r.logger.Error("cannot handle action", logging.Err(err))
 		return
 	}
 
-	writer.WriteHeader(http.StatusOK)
+	writer.Header().Set("Content-Type", "application/json")
+
+	if _, err := writer.Write(response.ToJson()); err != nil {
+		r.logger.Error("cannot write action response", logging.Err(err))
+	}
+}
+
+// executeAction executes the action on the report and returns
+// a response, which contains the re-rendered post.
+func (r *ReportEndpoints) executeAction(action *mattermost.Action, postID string) (*model.PostActionIntegrationResponse, error) {
+	report, err := r.storage.ReadByReportID(action.ReportID)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		return &model.PostActionIntegrationResponse{EphemeralText: r.res.ReportNotFound(action.ReportID.String())}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read report with given id: %w", err)
+	}
+
+	if report.PostID != postID {
+		return nil, fmt.Errorf("report %v does not belong to post %v", report.ID.String(), postID)
+	}
+
+	// A submitted report is closed, no action may change it
+	if report.ReportStopped {
+		return &model.PostActionIntegrationResponse{EphemeralText: r.res.ReportAlreadySubmitted(report.ReportStoppedBy)}, nil
+	}
+
+	switch action.Name {
+	case mattermost.ActionSubmit:
+		err = r.reporter.SubmitReport(report.ID, action.Username)
+	case mattermost.ActionTake:
+		err = r.reporter.TakeReport(report.ID, action.Username)
+	case mattermost.ActionSnoozeOneHour:
+		err = r.reporter.SnoozeReport(report.ID, time.Now().Add(time.Hour))
+	case mattermost.ActionSnoozeOneDay:
+		err = r.reporter.SnoozeReport(report.ID, time.Now().Add(24*time.Hour))
+	case mattermost.ActionMute:
+		err = r.reporter.MuteReason(report.Namespace, report.Reason, time.Now().Add(muteDuration), action.Username)
+	default:
+		return &model.PostActionIntegrationResponse{EphemeralText: r.res.UnknownAction(action.Name)}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.handler.RenderPost(report.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PostActionIntegrationResponse{Update: post}, nil
 }
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"k8sbot/internal/listener"
+	"k8sbot/internal/logging"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type healthResponse struct {
+	Status string                    `json:"status"`
+	Checks map[string]*checkResponse `json:"checks"`
+}
+
+// checkResponse is the result of a single check. The last success
+// and the last error are only known for the listeners.
+type checkResponse struct {
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// HealthEndpoints serves the probes for kubernetes:
+//   - /healthz fails, when a listener had no successful cycle recently
+//   - /readyz fails additionally, when mattermost isn't reachable
+type HealthEndpoints struct {
+	listeners       []listener.Listener
+	checkMattermost func() error
+	logger          *slog.Logger
+}
+
+func NewHealthEndpoints(serveMux *http.ServeMux, listeners []listener.Listener, checkMattermost func() error, logger *slog.Logger) *HealthEndpoints {
+	h := &HealthEndpoints{
+		listeners:       listeners,
+		checkMattermost: checkMattermost,
+		logger:          logger.With(logging.Component, "health"),
+	}
+
+	serveMux.HandleFunc("/healthz", h.handleHealth)
+	serveMux.HandleFunc("/readyz", h.handleReady)
+
+	return h
+}
+
+func (h *HealthEndpoints) handleHealth(writer http.ResponseWriter, request *http.Request) {
+	h.writeChecks(writer, h.checkListeners())
+}
+
+func (h *HealthEndpoints) handleReady(writer http.ResponseWriter, request *http.Request) {
+	checks := h.checkListeners()
+	checks["mattermost"] = newCheckResponse(h.checkMattermost())
+
+	h.writeChecks(writer, checks)
+}
+
+func (h *HealthEndpoints) checkListeners() map[string]*checkResponse {
+	checks := map[string]*checkResponse{}
+	now := time.Now()
+
+	for _, l := range h.listeners {
+		health := l.Health()
+		check := newCheckResponse(health.Check(now))
+
+		if lastSuccess := health.LastSuccess(); !lastSuccess.IsZero() {
+			check.LastSuccess = &lastSuccess
+		}
+
+		if err := health.LastError(); err != nil {
+			check.LastError = err.Error()
+		}
+
+		checks[l.Name()] = check
+	}
+
+	return checks
+}
+
+func newCheckResponse(err error) *checkResponse {
+	if err != nil {
+		return &checkResponse{Status: "failed", Error: err.Error()}
+	}
+
+	return &checkResponse{Status: "ok"}
+}
+
+// writeChecks responds with 200, when every check passed,
+// otherwise with 503. The body contains the result of every check.
+func (h *HealthEndpoints) writeChecks(writer http.ResponseWriter, checks map[string]*checkResponse) {
+	response := &healthResponse{
+		Status: "ok",
+		Checks: checks,
+	}
+
+	status := http.StatusOK
+
+	for _, check := range checks {
+		if check.Error != "" {
+			response.Status = "failed"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(h.logger, writer, status, response)
+}
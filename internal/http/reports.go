@@ -0,0 +1,250 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/golangee/uuid"
+	"github.com/gorilla/mux"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reporter"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	stateOpen       = "open"
+	stateInProgress = "in_progress"
+	stateSubmitted  = "submitted"
+	// apiUser is used as user, who submitted a report
+	// over the api without naming a user.
+	apiUser = "api"
+)
+
+type reportResponse struct {
+	ID               string     `json:"id"`
+	ObjectID         string     `json:"object_id"`
+	PostID           string     `json:"post_id,omitempty"`
+	Namespace        string     `json:"namespace"`
+	Reason           string     `json:"reason"`
+	Object           string     `json:"object"`
+	Message          string     `json:"message"`
+	Count            int32      `json:"count"`
+	ReportTimes      int        `json:"report_times"`
+	State            string     `json:"state"`
+	AssignedTo       string     `json:"assigned_to,omitempty"`
+	SnoozedUntil     *time.Time `json:"snoozed_until,omitempty"`
+	LastNotification time.Time  `json:"last_notification"`
+	CreatedAt        time.Time  `json:"created_at"`
+	SubmittedBy      string     `json:"submitted_by,omitempty"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+}
+
+type ackRequest struct {
+	User string `json:"user"`
+}
+
+// ApiReportEndpoints serves the reports over the rest api:
+//   - GET /api/v1/reports lists the reports, filtered by the
+//     query parameters namespace, reason and state
+//   - GET /api/v1/reports/{id} returns a report
+//   - POST /api/v1/reports/{id}/ack submits a report
+//   - DELETE /api/v1/reports/{id} removes a report from the storage
+type ApiReportEndpoints struct {
+	reporter *reporter.Reporter
+	storage  reportstorage.ReportStorage
+	logger   *slog.Logger
+}
+
+func NewApiReportEndpoints(router *mux.Router, reporter *reporter.Reporter, storage reportstorage.ReportStorage, logger *slog.Logger) *ApiReportEndpoints {
+	a := &ApiReportEndpoints{
+		reporter: reporter,
+		storage:  storage,
+		logger:   logger.With(logging.Component, "api"),
+	}
+
+	router.HandleFunc("/reports", a.handleList).Methods(http.MethodGet)
+	router.HandleFunc("/reports/{id}", a.handleGet).Methods(http.MethodGet)
+	router.HandleFunc("/reports/{id}/ack", a.handleAck).Methods(http.MethodPost)
+	router.HandleFunc("/reports/{id}", a.handleDelete).Methods(http.MethodDelete)
+
+	return a
+}
+
+func (a *ApiReportEndpoints) handleList(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	namespace, reason, state := query.Get("namespace"), query.Get("reason"), query.Get("state")
+
+	if state != "" && state != stateOpen && state != stateInProgress && state != stateSubmitted {
+		http.Error(writer, "state must be open, in_progress or submitted", http.StatusBadRequest)
+		return
+	}
+
+	reports, err := a.storage.ReadAll()
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		a.logger.Error("cannot read reports", logging.Err(err))
+		return
+	}
+
+	responses := []*reportResponse{}
+
+	for _, r := range reports {
+		response := newReportResponse(r)
+
+		if (namespace != "" && r.Namespace != namespace) || (reason != "" && r.Reason != reason) {
+			continue
+		}
+
+		if !matchesState(response, state) {
+			continue
+		}
+
+		responses = append(responses, response)
+	}
+
+	writeJSON(a.logger, writer, http.StatusOK, responses)
+}
+
+func (a *ApiReportEndpoints) handleGet(writer http.ResponseWriter, request *http.Request) {
+	report := a.readReport(writer, request)
+
+	if report == nil {
+		return
+	}
+
+	writeJSON(a.logger, writer, http.StatusOK, newReportResponse(report))
+}
+
+// handleAck submits the report in the name of the user of the
+// optional request body. Already submitted reports stay unchanged.
+func (a *ApiReportEndpoints) handleAck(writer http.ResponseWriter, request *http.Request) {
+	report := a.readReport(writer, request)
+
+	if report == nil {
+		return
+	}
+
+	ack := &ackRequest{}
+
+	if request.ContentLength != 0 {
+		if err := json.NewDecoder(request.Body).Decode(ack); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if ack.User == "" {
+		ack.User = apiUser
+	}
+
+	if !report.ReportStopped {
+		if err := a.reporter.SubmitReport(report.ID, ack.User); err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			a.logger.Error("cannot submit report", append(report.LogAttrs(), logging.Err(err))...)
+			return
+		}
+	}
+
+	report, err := a.storage.ReadByReportID(report.ID)
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		a.logger.Error("cannot read report", logging.Err(err))
+		return
+	}
+
+	writeJSON(a.logger, writer, http.StatusOK, newReportResponse(report))
+}
+
+// handleDelete removes the report, the post in the chat
+// stays. New events of the object create a new report.
+func (a *ApiReportEndpoints) handleDelete(writer http.ResponseWriter, request *http.Request) {
+	report := a.readReport(writer, request)
+
+	if report == nil {
+		return
+	}
+
+	if err := a.reporter.DeleteReport(report.ID); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		a.logger.Error("cannot delete report", append(report.LogAttrs(), logging.Err(err))...)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// readReport reads the report of the id in the path. When
+// the report cannot be read, the error is written and nil
+// is returned.
+func (a *ApiReportEndpoints) readReport(writer http.ResponseWriter, request *http.Request) *reportstorage.Report {
+	id, err := uuid.Parse(mux.Vars(request)["id"])
+
+	if err != nil {
+		http.Error(writer, "invalid report id", http.StatusBadRequest)
+		return nil
+	}
+
+	report, err := a.storage.ReadByReportID(id)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		http.Error(writer, "report not found", http.StatusNotFound)
+		return nil
+	} else if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		a.logger.Error("cannot read report", logging.Err(err))
+		return nil
+	}
+
+	return report
+}
+
+// matchesState reports whether the report has the given state.
+// Reports in progress are open as well.
+func matchesState(response *reportResponse, state string) bool {
+	switch state {
+	case "":
+		return true
+	case stateOpen:
+		return response.State != stateSubmitted
+	default:
+		return response.State == state
+	}
+}
+
+func newReportResponse(report *reportstorage.Report) *reportResponse {
+	response := &reportResponse{
+		ID:               report.ID.String(),
+		ObjectID:         string(report.ReportedObject),
+		PostID:           report.PostID,
+		Namespace:        report.Namespace,
+		Reason:           report.Reason,
+		Object:           report.Resource,
+		Message:          report.Msg,
+		Count:            report.Count,
+		ReportTimes:      report.ReportTimes,
+		State:            stateOpen,
+		AssignedTo:       report.AssignedTo,
+		LastNotification: report.LastReportUpdate,
+		CreatedAt:        report.CreatedAt,
+	}
+
+	if report.IsSnoozed(time.Now()) {
+		until := report.SnoozedUntil
+		response.SnoozedUntil = &until
+	}
+
+	if report.ReportStopped {
+		at := report.ReportStoppedAt
+		response.State = stateSubmitted
+		response.SubmittedBy = report.ReportStoppedBy
+		response.SubmittedAt = &at
+	} else if report.IsInProgress {
+		response.State = stateInProgress
+	}
+
+	return response
+}
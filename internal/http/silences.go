@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/golangee/uuid"
+	"github.com/gorilla/mux"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reporter"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// SilenceEndpoints manages the silences over the rest api:
+//   - GET /api/v1/silences lists the active and upcoming silences
+//   - POST /api/v1/silences creates a silence
+//   - DELETE /api/v1/silences/{id} ends a silence
+type SilenceEndpoints struct {
+	reporter *reporter.Reporter
+	storage  reportstorage.ReportStorage
+	logger   *slog.Logger
+}
+
+func NewSilenceEndpoints(router *mux.Router, reporter *reporter.Reporter, storage reportstorage.ReportStorage, logger *slog.Logger) *SilenceEndpoints {
+	s := &SilenceEndpoints{
+		reporter: reporter,
+		storage:  storage,
+		logger:   logger.With(logging.Component, "api"),
+	}
+
+	router.HandleFunc("/silences", s.handleList).Methods(http.MethodGet)
+	router.HandleFunc("/silences", s.handleCreate).Methods(http.MethodPost)
+	router.HandleFunc("/silences/{id}", s.handleDelete).Methods(http.MethodDelete)
+
+	return s
+}
+
+func (s *SilenceEndpoints) handleList(writer http.ResponseWriter, request *http.Request) {
+	silences, err := s.storage.ReadSilences()
+
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		s.logger.Error("cannot read silences", logging.Err(err))
+		return
+	}
+
+	writeJSON(s.logger, writer, http.StatusOK, silences)
+}
+
+// handleCreate creates the silence of the request body. Without
+// start, the silence starts immediately. The id is always generated.
+func (s *SilenceEndpoints) handleCreate(writer http.ResponseWriter, request *http.Request) {
+	silence := &reportstorage.Silence{}
+
+	if err := json.NewDecoder(request.Body).Decode(silence); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	silence.ID = uuid.New()
+
+	if silence.Start.IsZero() {
+		silence.Start = time.Now()
+	}
+
+	if err := silence.Validate(); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.reporter.AddSilence(silence); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		s.logger.Error("cannot add silence", logging.Err(err))
+		return
+	}
+
+	writeJSON(s.logger, writer, http.StatusCreated, silence)
+}
+
+func (s *SilenceEndpoints) handleDelete(writer http.ResponseWriter, request *http.Request) {
+	id, err := uuid.Parse(mux.Vars(request)["id"])
+
+	if err != nil {
+		http.Error(writer, "invalid silence id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.reporter.DeleteSilence(id); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		s.logger.Error("cannot delete silence", logging.Err(err))
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -14,21 +14,75 @@ func init() {
 	// from strings-de-DE.xml
 	tag = "de-DE"
 
+	i18n.ImportValue(i18n.NewText(tag, "active_mutes", "Stummgeschaltete Gründe"))
+	i18n.ImportValue(i18n.NewText(tag, "alert_storm", "Alarmsturm"))
+	i18n.ImportValue(i18n.NewText(tag, "assigned_to", "Übernommen von"))
+	i18n.ImportValue(i18n.NewText(tag, "cluster", "Cluster"))
+	i18n.ImportValue(i18n.NewText(tag, "command_usage", "Verfügbare Befehle: `list [namespace]`, `show <id>`, `ack <id>`, `mute <grund> <dauer>`, `silence <namespace|*> <grund|*> <dauer> [objekt]`, `unsilence <id>`, `status`"))
 	i18n.ImportValue(i18n.NewText(tag, "count", "Anzahl"))
 	i18n.ImportValue(i18n.NewText(tag, "count_report_from_bot", "Meldungswiederholungen vom Bot"))
+	i18n.ImportValue(i18n.NewText(tag, "created_at", "Erstellt am"))
+	i18n.ImportValue(i18n.NewText(tag, "created_by", "Angelegt von"))
+	i18n.ImportValue(i18n.NewText(tag, "dashboard_title", "K8S-Event-Bot"))
+	i18n.ImportValue(i18n.NewText(tag, "email_footer", "Diese E-Mail wurde automatisch vom K8S-Event-Bot versendet."))
+	i18n.ImportValue(i18n.NewText(tag, "email_subject_digest", "Zusammenfassung: %d Meldungen"))
+	i18n.ImportValue(i18n.NewText(tag, "email_subject_report", "Warnung: %s/%s"))
+	i18n.ImportValue(i18n.NewText(tag, "email_subject_resolved", "Bestätigt: %s/%s"))
+	i18n.ImportValue(i18n.NewText(tag, "email_subject_update", "Aktualisierung: %s/%s"))
+	i18n.ImportValue(i18n.NewText(tag, "end", "Ende"))
+	i18n.ImportValue(i18n.NewText(tag, "followup_count_jump", "Die Anzahl ist sprunghaft von %d auf %d gestiegen!"))
+	i18n.ImportValue(i18n.NewText(tag, "followup_occurred_again", "Das Ereignis ist erneut aufgetreten. Anzahl: %d (+%d)"))
+	i18n.ImportValue(i18n.NewText(tag, "followup_reopened", "Das Ereignis ist nach der Bestätigung erneut aufgetreten, die Meldung wurde wieder geöffnet. Anzahl: %d"))
+	i18n.ImportValue(i18n.NewText(tag, "followup_resolved", "Die Meldung wurde von %s bestätigt."))
+	i18n.ImportValue(i18n.NewText(tag, "id", "ID"))
 	i18n.ImportValue(i18n.NewText(tag, "info", "Information"))
 	i18n.ImportValue(i18n.NewText(tag, "internal_error", "Interner Fehler"))
+	i18n.ImportValue(i18n.NewText(tag, "invalid_duration", "Ungültige Dauer: %s"))
+	i18n.ImportValue(i18n.NewText(tag, "invalid_report_id", "Ungültige Meldungs-ID: %s"))
+	i18n.ImportValue(i18n.NewText(tag, "invalid_silence", "Ungültiges Wartungsfenster: %s"))
 	i18n.ImportValue(i18n.NewText(tag, "last_seen", "Zu letzt gesehen"))
 	i18n.ImportValue(i18n.NewText(tag, "message", "Nachricht"))
+	i18n.ImportValue(i18n.NewText(tag, "mute_entry", "%s bis %s (von %s)"))
+	i18n.ImportValue(i18n.NewText(tag, "mute_reason_in_namespace", "Grund im Namespace stummschalten"))
+	i18n.ImportValue(i18n.NewText(tag, "muted_until", "Stummgeschaltet bis"))
 	i18n.ImportValue(i18n.NewText(tag, "namespace", "Namespace"))
+	i18n.ImportValue(i18n.NewText(tag, "no_active_mutes", "Keine stummgeschalteten Gründe."))
+	i18n.ImportValue(i18n.NewText(tag, "no_open_reports", "Keine offenen Meldungen."))
+	i18n.ImportValue(i18n.NewText(tag, "no_reports", "Keine Meldungen in den letzten sieben Tagen."))
+	i18n.ImportValue(i18n.NewText(tag, "no_silences", "Keine Wartungsfenster."))
+	i18n.ImportValue(i18n.NewText(tag, "no_submitted_reports", "Keine bestätigten Meldungen."))
 	i18n.ImportValue(i18n.NewText(tag, "object", "Resource"))
+	i18n.ImportValue(i18n.NewText(tag, "open_reports", "Offene Meldungen"))
 	i18n.ImportValue(i18n.NewText(tag, "pod", "Pod"))
+	i18n.ImportValue(i18n.NewText(tag, "post", "Beitrag"))
 	i18n.ImportValue(i18n.NewText(tag, "reason", "Grund"))
+	i18n.ImportValue(i18n.NewText(tag, "reason_muted", "Meldungen mit dem Grund %s sind bis %s stummgeschaltet."))
+	i18n.ImportValue(i18n.NewText(tag, "report_already_submitted", "Die Meldung wurde bereits von %s bestätigt."))
+	i18n.ImportValue(i18n.NewText(tag, "report_not_found", "Keine Meldung mit der ID %s gefunden."))
+	i18n.ImportValue(i18n.NewText(tag, "report_submitted", "Meldung %s wurde bestätigt."))
+	i18n.ImportValue(i18n.NewText(tag, "reports_per_day", "Meldungen pro Tag"))
 	i18n.ImportValue(i18n.NewText(tag, "restarts", "Neustarts"))
+	i18n.ImportValue(i18n.NewText(tag, "show_post", "Anzeigen"))
+	i18n.ImportValue(i18n.NewText(tag, "silence_created", "Wartungsfenster %s wurde angelegt und endet um %s."))
+	i18n.ImportValue(i18n.NewText(tag, "silence_deleted", "Wartungsfenster %s wurde beendet."))
+	i18n.ImportValue(i18n.NewText(tag, "silence_not_found", "Kein Wartungsfenster mit der ID %s gefunden."))
+	i18n.ImportValue(i18n.NewText(tag, "silences", "Wartungsfenster"))
+	i18n.ImportValue(i18n.NewText(tag, "snooze_one_day", "24h pausieren"))
+	i18n.ImportValue(i18n.NewText(tag, "snooze_one_hour", "1h pausieren"))
+	i18n.ImportValue(i18n.NewText(tag, "snoozed_until", "Pausiert bis"))
+	i18n.ImportValue(i18n.NewText(tag, "start", "Beginn"))
+	i18n.ImportValue(i18n.NewText(tag, "state", "Status"))
+	i18n.ImportValue(i18n.NewText(tag, "state_open", "Offen"))
+	i18n.ImportValue(i18n.NewText(tag, "state_submitted", "Bestätigt"))
+	i18n.ImportValue(i18n.NewText(tag, "status_summary", "Offene Meldungen: %d, bestätigte Meldungen: %d"))
+	i18n.ImportValue(i18n.NewText(tag, "storm_summary", "Während des Alarmsturms wurden %d Meldungen zusammengefasst:"))
 	i18n.ImportValue(i18n.NewText(tag, "submit", "Bestätigen"))
 	i18n.ImportValue(i18n.NewText(tag, "submitted_at", "Bestätigt um"))
 	i18n.ImportValue(i18n.NewText(tag, "submitted_by", "Bestätigt von"))
+	i18n.ImportValue(i18n.NewText(tag, "submitted_reports", "Bestätigte Meldungen"))
+	i18n.ImportValue(i18n.NewText(tag, "take_it", "Übernehmen"))
 	i18n.ImportValue(i18n.NewText(tag, "unexpected_event", ""))
+	i18n.ImportValue(i18n.NewText(tag, "unknown_action", "Unbekannte Aktion: %s"))
 	i18n.ImportValue(i18n.NewText(tag, "warning", "Warnung"))
 	i18n.ImportValue(i18n.NewText(tag, "warning_restart_pod", "Der angegebene Pod startet aktuell öfters neu!"))
 	_ = tag
@@ -45,6 +99,51 @@ func NewResources(locale string) Resources {
 	return Resources{i18n.From(locale)}
 }
 
+// ActiveMutes returns a translated text for "Stummgeschaltete Gründe"
+func (r Resources) ActiveMutes() string {
+	str, err := r.res.Text("active_mutes")
+	if err != nil {
+		return fmt.Errorf("MISS!active_mutes: %w", err).Error()
+	}
+	return str
+}
+
+// AlertStorm returns a translated text for "Alarmsturm"
+func (r Resources) AlertStorm() string {
+	str, err := r.res.Text("alert_storm")
+	if err != nil {
+		return fmt.Errorf("MISS!alert_storm: %w", err).Error()
+	}
+	return str
+}
+
+// AssignedTo returns a translated text for "Übernommen von"
+func (r Resources) AssignedTo() string {
+	str, err := r.res.Text("assigned_to")
+	if err != nil {
+		return fmt.Errorf("MISS!assigned_to: %w", err).Error()
+	}
+	return str
+}
+
+// Cluster returns a translated text for "Cluster"
+func (r Resources) Cluster() string {
+	str, err := r.res.Text("cluster")
+	if err != nil {
+		return fmt.Errorf("MISS!cluster: %w", err).Error()
+	}
+	return str
+}
+
+// CommandUsage returns a translated text for "Verfügbare Befehle: `list [namespace]`, `show <id>`, `ack <id>`, `mute <grund> <dauer>`, `silence <namespace|*> <grund|*> <dauer> [objekt]`, `unsilence <id>`, `status`"
+func (r Resources) CommandUsage() string {
+	str, err := r.res.Text("command_usage")
+	if err != nil {
+		return fmt.Errorf("MISS!command_usage: %w", err).Error()
+	}
+	return str
+}
+
 // Count returns a translated text for "Anzahl"
 func (r Resources) Count() string {
 	str, err := r.res.Text("count")
@@ -63,6 +162,132 @@ func (r Resources) CountReportFromBot() string {
 	return str
 }
 
+// CreatedAt returns a translated text for "Erstellt am"
+func (r Resources) CreatedAt() string {
+	str, err := r.res.Text("created_at")
+	if err != nil {
+		return fmt.Errorf("MISS!created_at: %w", err).Error()
+	}
+	return str
+}
+
+// CreatedBy returns a translated text for "Angelegt von"
+func (r Resources) CreatedBy() string {
+	str, err := r.res.Text("created_by")
+	if err != nil {
+		return fmt.Errorf("MISS!created_by: %w", err).Error()
+	}
+	return str
+}
+
+// DashboardTitle returns a translated text for "K8S-Event-Bot"
+func (r Resources) DashboardTitle() string {
+	str, err := r.res.Text("dashboard_title")
+	if err != nil {
+		return fmt.Errorf("MISS!dashboard_title: %w", err).Error()
+	}
+	return str
+}
+
+// EmailFooter returns a translated text for "Diese E-Mail wurde automatisch vom K8S-Event-Bot versendet."
+func (r Resources) EmailFooter() string {
+	str, err := r.res.Text("email_footer")
+	if err != nil {
+		return fmt.Errorf("MISS!email_footer: %w", err).Error()
+	}
+	return str
+}
+
+// EmailSubjectDigest returns a translated text for "Zusammenfassung: %d Meldungen"
+func (r Resources) EmailSubjectDigest(num0 int) string {
+	str, err := r.res.Text("email_subject_digest", num0)
+	if err != nil {
+		return fmt.Errorf("MISS!email_subject_digest: %w", err).Error()
+	}
+	return str
+}
+
+// EmailSubjectReport returns a translated text for "Warnung: %s/%s"
+func (r Resources) EmailSubjectReport(str0 string, str1 string) string {
+	str, err := r.res.Text("email_subject_report", str0, str1)
+	if err != nil {
+		return fmt.Errorf("MISS!email_subject_report: %w", err).Error()
+	}
+	return str
+}
+
+// EmailSubjectResolved returns a translated text for "Bestätigt: %s/%s"
+func (r Resources) EmailSubjectResolved(str0 string, str1 string) string {
+	str, err := r.res.Text("email_subject_resolved", str0, str1)
+	if err != nil {
+		return fmt.Errorf("MISS!email_subject_resolved: %w", err).Error()
+	}
+	return str
+}
+
+// EmailSubjectUpdate returns a translated text for "Aktualisierung: %s/%s"
+func (r Resources) EmailSubjectUpdate(str0 string, str1 string) string {
+	str, err := r.res.Text("email_subject_update", str0, str1)
+	if err != nil {
+		return fmt.Errorf("MISS!email_subject_update: %w", err).Error()
+	}
+	return str
+}
+
+// End returns a translated text for "Ende"
+func (r Resources) End() string {
+	str, err := r.res.Text("end")
+	if err != nil {
+		return fmt.Errorf("MISS!end: %w", err).Error()
+	}
+	return str
+}
+
+// FollowupCountJump returns a translated text for "Die Anzahl ist sprunghaft von %d auf %d gestiegen!"
+func (r Resources) FollowupCountJump(num0 int, num1 int) string {
+	str, err := r.res.Text("followup_count_jump", num0, num1)
+	if err != nil {
+		return fmt.Errorf("MISS!followup_count_jump: %w", err).Error()
+	}
+	return str
+}
+
+// FollowupOccurredAgain returns a translated text for "Das Ereignis ist erneut aufgetreten. Anzahl: %d (+%d)"
+func (r Resources) FollowupOccurredAgain(num0 int, num1 int) string {
+	str, err := r.res.Text("followup_occurred_again", num0, num1)
+	if err != nil {
+		return fmt.Errorf("MISS!followup_occurred_again: %w", err).Error()
+	}
+	return str
+}
+
+// FollowupReopened returns a translated text for "Das Ereignis ist nach der Bestätigung erneut aufgetreten, die Meldung wurde wieder geöffnet. Anzahl: %d"
+func (r Resources) FollowupReopened(num0 int) string {
+	str, err := r.res.Text("followup_reopened", num0)
+	if err != nil {
+		return fmt.Errorf("MISS!followup_reopened: %w", err).Error()
+	}
+	return str
+}
+
+// FollowupResolved returns a translated text for "Die Meldung wurde von %s bestätigt."
+func (r Resources) FollowupResolved(str0 string) string {
+	str, err := r.res.Text("followup_resolved", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!followup_resolved: %w", err).Error()
+	}
+	return str
+}
+
+// Id returns a translated text for "ID"
+func (r Resources) Id() string {
+	str, err := r.res.Text("id")
+	if err != nil {
+		return fmt.Errorf("MISS!id: %w", err).Error()
+	}
+	return str
+}
+
 // Info returns a translated text for "Information"
 func (r Resources) Info() string {
 	str, err := r.res.Text("info")
@@ -81,6 +306,33 @@ func (r Resources) InternalError() string {
 	return str
 }
 
+// InvalidDuration returns a translated text for "Ungültige Dauer: %s"
+func (r Resources) InvalidDuration(str0 string) string {
+	str, err := r.res.Text("invalid_duration", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!invalid_duration: %w", err).Error()
+	}
+	return str
+}
+
+// InvalidReportId returns a translated text for "Ungültige Meldungs-ID: %s"
+func (r Resources) InvalidReportId(str0 string) string {
+	str, err := r.res.Text("invalid_report_id", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!invalid_report_id: %w", err).Error()
+	}
+	return str
+}
+
+// InvalidSilence returns a translated text for "Ungültiges Wartungsfenster: %s"
+func (r Resources) InvalidSilence(str0 string) string {
+	str, err := r.res.Text("invalid_silence", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!invalid_silence: %w", err).Error()
+	}
+	return str
+}
+
 // LastSeen returns a translated text for "Zu letzt gesehen"
 func (r Resources) LastSeen() string {
 	str, err := r.res.Text("last_seen")
@@ -99,6 +351,33 @@ func (r Resources) Message() string {
 	return str
 }
 
+// MuteEntry returns a translated text for "%s bis %s (von %s)"
+func (r Resources) MuteEntry(str0 string, str1 string, str2 string) string {
+	str, err := r.res.Text("mute_entry", str0, str1, str2)
+	if err != nil {
+		return fmt.Errorf("MISS!mute_entry: %w", err).Error()
+	}
+	return str
+}
+
+// MuteReasonInNamespace returns a translated text for "Grund im Namespace stummschalten"
+func (r Resources) MuteReasonInNamespace() string {
+	str, err := r.res.Text("mute_reason_in_namespace")
+	if err != nil {
+		return fmt.Errorf("MISS!mute_reason_in_namespace: %w", err).Error()
+	}
+	return str
+}
+
+// MutedUntil returns a translated text for "Stummgeschaltet bis"
+func (r Resources) MutedUntil() string {
+	str, err := r.res.Text("muted_until")
+	if err != nil {
+		return fmt.Errorf("MISS!muted_until: %w", err).Error()
+	}
+	return str
+}
+
 // Namespace returns a translated text for "Namespace"
 func (r Resources) Namespace() string {
 	str, err := r.res.Text("namespace")
@@ -108,6 +387,51 @@ func (r Resources) Namespace() string {
 	return str
 }
 
+// NoActiveMutes returns a translated text for "Keine stummgeschalteten Gründe."
+func (r Resources) NoActiveMutes() string {
+	str, err := r.res.Text("no_active_mutes")
+	if err != nil {
+		return fmt.Errorf("MISS!no_active_mutes: %w", err).Error()
+	}
+	return str
+}
+
+// NoOpenReports returns a translated text for "Keine offenen Meldungen."
+func (r Resources) NoOpenReports() string {
+	str, err := r.res.Text("no_open_reports")
+	if err != nil {
+		return fmt.Errorf("MISS!no_open_reports: %w", err).Error()
+	}
+	return str
+}
+
+// NoReports returns a translated text for "Keine Meldungen in den letzten sieben Tagen."
+func (r Resources) NoReports() string {
+	str, err := r.res.Text("no_reports")
+	if err != nil {
+		return fmt.Errorf("MISS!no_reports: %w", err).Error()
+	}
+	return str
+}
+
+// NoSilences returns a translated text for "Keine Wartungsfenster."
+func (r Resources) NoSilences() string {
+	str, err := r.res.Text("no_silences")
+	if err != nil {
+		return fmt.Errorf("MISS!no_silences: %w", err).Error()
+	}
+	return str
+}
+
+// NoSubmittedReports returns a translated text for "Keine bestätigten Meldungen."
+func (r Resources) NoSubmittedReports() string {
+	str, err := r.res.Text("no_submitted_reports")
+	if err != nil {
+		return fmt.Errorf("MISS!no_submitted_reports: %w", err).Error()
+	}
+	return str
+}
+
 // Object returns a translated text for "Resource"
 func (r Resources) Object() string {
 	str, err := r.res.Text("object")
@@ -117,6 +441,15 @@ func (r Resources) Object() string {
 	return str
 }
 
+// OpenReports returns a translated text for "Offene Meldungen"
+func (r Resources) OpenReports() string {
+	str, err := r.res.Text("open_reports")
+	if err != nil {
+		return fmt.Errorf("MISS!open_reports: %w", err).Error()
+	}
+	return str
+}
+
 // Pod returns a translated text for "Pod"
 func (r Resources) Pod() string {
 	str, err := r.res.Text("pod")
@@ -126,6 +459,15 @@ func (r Resources) Pod() string {
 	return str
 }
 
+// Post returns a translated text for "Beitrag"
+func (r Resources) Post() string {
+	str, err := r.res.Text("post")
+	if err != nil {
+		return fmt.Errorf("MISS!post: %w", err).Error()
+	}
+	return str
+}
+
 // Reason returns a translated text for "Grund"
 func (r Resources) Reason() string {
 	str, err := r.res.Text("reason")
@@ -135,6 +477,51 @@ func (r Resources) Reason() string {
 	return str
 }
 
+// ReasonMuted returns a translated text for "Meldungen mit dem Grund %s sind bis %s stummgeschaltet."
+func (r Resources) ReasonMuted(str0 string, str1 string) string {
+	str, err := r.res.Text("reason_muted", str0, str1)
+	if err != nil {
+		return fmt.Errorf("MISS!reason_muted: %w", err).Error()
+	}
+	return str
+}
+
+// ReportAlreadySubmitted returns a translated text for "Die Meldung wurde bereits von %s bestätigt."
+func (r Resources) ReportAlreadySubmitted(str0 string) string {
+	str, err := r.res.Text("report_already_submitted", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!report_already_submitted: %w", err).Error()
+	}
+	return str
+}
+
+// ReportNotFound returns a translated text for "Keine Meldung mit der ID %s gefunden."
+func (r Resources) ReportNotFound(str0 string) string {
+	str, err := r.res.Text("report_not_found", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!report_not_found: %w", err).Error()
+	}
+	return str
+}
+
+// ReportSubmitted returns a translated text for "Meldung %s wurde bestätigt."
+func (r Resources) ReportSubmitted(str0 string) string {
+	str, err := r.res.Text("report_submitted", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!report_submitted: %w", err).Error()
+	}
+	return str
+}
+
+// ReportsPerDay returns a translated text for "Meldungen pro Tag"
+func (r Resources) ReportsPerDay() string {
+	str, err := r.res.Text("reports_per_day")
+	if err != nil {
+		return fmt.Errorf("MISS!reports_per_day: %w", err).Error()
+	}
+	return str
+}
+
 // Restarts returns a translated text for "Neustarts"
 func (r Resources) Restarts() string {
 	str, err := r.res.Text("restarts")
@@ -144,6 +531,132 @@ func (r Resources) Restarts() string {
 	return str
 }
 
+// ShowPost returns a translated text for "Anzeigen"
+func (r Resources) ShowPost() string {
+	str, err := r.res.Text("show_post")
+	if err != nil {
+		return fmt.Errorf("MISS!show_post: %w", err).Error()
+	}
+	return str
+}
+
+// SilenceCreated returns a translated text for "Wartungsfenster %s wurde angelegt und endet um %s."
+func (r Resources) SilenceCreated(str0 string, str1 string) string {
+	str, err := r.res.Text("silence_created", str0, str1)
+	if err != nil {
+		return fmt.Errorf("MISS!silence_created: %w", err).Error()
+	}
+	return str
+}
+
+// SilenceDeleted returns a translated text for "Wartungsfenster %s wurde beendet."
+func (r Resources) SilenceDeleted(str0 string) string {
+	str, err := r.res.Text("silence_deleted", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!silence_deleted: %w", err).Error()
+	}
+	return str
+}
+
+// SilenceNotFound returns a translated text for "Kein Wartungsfenster mit der ID %s gefunden."
+func (r Resources) SilenceNotFound(str0 string) string {
+	str, err := r.res.Text("silence_not_found", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!silence_not_found: %w", err).Error()
+	}
+	return str
+}
+
+// Silences returns a translated text for "Wartungsfenster"
+func (r Resources) Silences() string {
+	str, err := r.res.Text("silences")
+	if err != nil {
+		return fmt.Errorf("MISS!silences: %w", err).Error()
+	}
+	return str
+}
+
+// SnoozeOneDay returns a translated text for "24h pausieren"
+func (r Resources) SnoozeOneDay() string {
+	str, err := r.res.Text("snooze_one_day")
+	if err != nil {
+		return fmt.Errorf("MISS!snooze_one_day: %w", err).Error()
+	}
+	return str
+}
+
+// SnoozeOneHour returns a translated text for "1h pausieren"
+func (r Resources) SnoozeOneHour() string {
+	str, err := r.res.Text("snooze_one_hour")
+	if err != nil {
+		return fmt.Errorf("MISS!snooze_one_hour: %w", err).Error()
+	}
+	return str
+}
+
+// SnoozedUntil returns a translated text for "Pausiert bis"
+func (r Resources) SnoozedUntil() string {
+	str, err := r.res.Text("snoozed_until")
+	if err != nil {
+		return fmt.Errorf("MISS!snoozed_until: %w", err).Error()
+	}
+	return str
+}
+
+// Start returns a translated text for "Beginn"
+func (r Resources) Start() string {
+	str, err := r.res.Text("start")
+	if err != nil {
+		return fmt.Errorf("MISS!start: %w", err).Error()
+	}
+	return str
+}
+
+// State returns a translated text for "Status"
+func (r Resources) State() string {
+	str, err := r.res.Text("state")
+	if err != nil {
+		return fmt.Errorf("MISS!state: %w", err).Error()
+	}
+	return str
+}
+
+// StateOpen returns a translated text for "Offen"
+func (r Resources) StateOpen() string {
+	str, err := r.res.Text("state_open")
+	if err != nil {
+		return fmt.Errorf("MISS!state_open: %w", err).Error()
+	}
+	return str
+}
+
+// StateSubmitted returns a translated text for "Bestätigt"
+func (r Resources) StateSubmitted() string {
+	str, err := r.res.Text("state_submitted")
+	if err != nil {
+		return fmt.Errorf("MISS!state_submitted: %w", err).Error()
+	}
+	return str
+}
+
+// StatusSummary returns a translated text for "Offene Meldungen: %d, bestätigte Meldungen: %d"
+func (r Resources) StatusSummary(num0 int, num1 int) string {
+	str, err := r.res.Text("status_summary", num0, num1)
+	if err != nil {
+		return fmt.Errorf("MISS!status_summary: %w", err).Error()
+	}
+	return str
+}
+
+// StormSummary returns a translated text for "Während des Alarmsturms wurden %d Meldungen zusammengefasst:"
+func (r Resources) StormSummary(num0 int) string {
+	str, err := r.res.Text("storm_summary", num0)
+	if err != nil {
+		return fmt.Errorf("MISS!storm_summary: %w", err).Error()
+	}
+	return str
+}
+
 // Submit returns a translated text for "Bestätigen"
 func (r Resources) Submit() string {
 	str, err := r.res.Text("submit")
@@ -171,6 +684,24 @@ func (r Resources) SubmittedBy() string {
 	return str
 }
 
+// SubmittedReports returns a translated text for "Bestätigte Meldungen"
+func (r Resources) SubmittedReports() string {
+	str, err := r.res.Text("submitted_reports")
+	if err != nil {
+		return fmt.Errorf("MISS!submitted_reports: %w", err).Error()
+	}
+	return str
+}
+
+// TakeIt returns a translated text for "Übernehmen"
+func (r Resources) TakeIt() string {
+	str, err := r.res.Text("take_it")
+	if err != nil {
+		return fmt.Errorf("MISS!take_it: %w", err).Error()
+	}
+	return str
+}
+
 // UnexpectedEvent returns a translated text for ""
 func (r Resources) UnexpectedEvent() string {
 	str, err := r.res.Text("unexpected_event")
@@ -180,6 +711,15 @@ func (r Resources) UnexpectedEvent() string {
 	return str
 }
 
+// UnknownAction returns a translated text for "Unbekannte Aktion: %s"
+func (r Resources) UnknownAction(str0 string) string {
+	str, err := r.res.Text("unknown_action", str0)
+	if err != nil {
+		return fmt.Errorf("MISS!unknown_action: %w", err).Error()
+	}
+	return str
+}
+
 // Warning returns a translated text for "Warnung"
 func (r Resources) Warning() string {
 	str, err := r.res.Text("warning")
@@ -201,21 +741,75 @@ func (r Resources) WarningRestartPod() string {
 // FuncMap returns the named functions to be used with a template
 func (r Resources) FuncMap() map[string]interface{} {
 	m := make(map[string]interface{})
+	m["ActiveMutes"] = r.ActiveMutes
+	m["AlertStorm"] = r.AlertStorm
+	m["AssignedTo"] = r.AssignedTo
+	m["Cluster"] = r.Cluster
+	m["CommandUsage"] = r.CommandUsage
 	m["Count"] = r.Count
 	m["CountReportFromBot"] = r.CountReportFromBot
+	m["CreatedAt"] = r.CreatedAt
+	m["CreatedBy"] = r.CreatedBy
+	m["DashboardTitle"] = r.DashboardTitle
+	m["EmailFooter"] = r.EmailFooter
+	m["EmailSubjectDigest"] = r.EmailSubjectDigest
+	m["EmailSubjectReport"] = r.EmailSubjectReport
+	m["EmailSubjectResolved"] = r.EmailSubjectResolved
+	m["EmailSubjectUpdate"] = r.EmailSubjectUpdate
+	m["End"] = r.End
+	m["FollowupCountJump"] = r.FollowupCountJump
+	m["FollowupOccurredAgain"] = r.FollowupOccurredAgain
+	m["FollowupReopened"] = r.FollowupReopened
+	m["FollowupResolved"] = r.FollowupResolved
+	m["Id"] = r.Id
 	m["Info"] = r.Info
 	m["InternalError"] = r.InternalError
+	m["InvalidDuration"] = r.InvalidDuration
+	m["InvalidReportId"] = r.InvalidReportId
+	m["InvalidSilence"] = r.InvalidSilence
 	m["LastSeen"] = r.LastSeen
 	m["Message"] = r.Message
+	m["MuteEntry"] = r.MuteEntry
+	m["MuteReasonInNamespace"] = r.MuteReasonInNamespace
+	m["MutedUntil"] = r.MutedUntil
 	m["Namespace"] = r.Namespace
+	m["NoActiveMutes"] = r.NoActiveMutes
+	m["NoOpenReports"] = r.NoOpenReports
+	m["NoReports"] = r.NoReports
+	m["NoSilences"] = r.NoSilences
+	m["NoSubmittedReports"] = r.NoSubmittedReports
 	m["Object"] = r.Object
+	m["OpenReports"] = r.OpenReports
 	m["Pod"] = r.Pod
+	m["Post"] = r.Post
 	m["Reason"] = r.Reason
+	m["ReasonMuted"] = r.ReasonMuted
+	m["ReportAlreadySubmitted"] = r.ReportAlreadySubmitted
+	m["ReportNotFound"] = r.ReportNotFound
+	m["ReportSubmitted"] = r.ReportSubmitted
+	m["ReportsPerDay"] = r.ReportsPerDay
 	m["Restarts"] = r.Restarts
+	m["ShowPost"] = r.ShowPost
+	m["SilenceCreated"] = r.SilenceCreated
+	m["SilenceDeleted"] = r.SilenceDeleted
+	m["SilenceNotFound"] = r.SilenceNotFound
+	m["Silences"] = r.Silences
+	m["SnoozeOneDay"] = r.SnoozeOneDay
+	m["SnoozeOneHour"] = r.SnoozeOneHour
+	m["SnoozedUntil"] = r.SnoozedUntil
+	m["Start"] = r.Start
+	m["State"] = r.State
+	m["StateOpen"] = r.StateOpen
+	m["StateSubmitted"] = r.StateSubmitted
+	m["StatusSummary"] = r.StatusSummary
+	m["StormSummary"] = r.StormSummary
 	m["Submit"] = r.Submit
 	m["SubmittedAt"] = r.SubmittedAt
 	m["SubmittedBy"] = r.SubmittedBy
+	m["SubmittedReports"] = r.SubmittedReports
+	m["TakeIt"] = r.TakeIt
 	m["UnexpectedEvent"] = r.UnexpectedEvent
+	m["UnknownAction"] = r.UnknownAction
 	m["Warning"] = r.Warning
 	m["WarningRestartPod"] = r.WarningRestartPod
 	return m
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	v13 "k8s.io/client-go/kubernetes/typed/coordination/v1"
 	v12 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
 	"os"
@@ -44,3 +45,7 @@ func (k *KubernetesApi) AppsV1() v1.AppsV1Interface {
 func (k *KubernetesApi) CoreV1() v12.CoreV1Interface {
 	return k.clientSet.CoreV1()
 }
+
+func (k *KubernetesApi) CoordinationV1() v13.CoordinationV1Interface {
+	return k.clientSet.CoordinationV1()
+}
@@ -0,0 +1,107 @@
+package listener
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// missedCycles is the number of cycles, a listener may fail or
+// hang, before it is reported as unhealthy.
+const missedCycles = 3
+
+// Health records the outcome of the cycles of a listener.
+type Health struct {
+	interval time.Duration
+
+	mutex       sync.Mutex
+	running     bool
+	started     time.Time
+	lastSuccess time.Time
+	lastError   error
+}
+
+// NewHealth creates the health of a listener, which
+// runs a cycle once per interval.
+func NewHealth(interval time.Duration) *Health {
+	return &Health{
+		interval: interval,
+	}
+}
+
+// Start marks the listener as running. A listener, which
+// doesn't run, e.g. on a follower, is always healthy.
+func (h *Health) Start(now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.running = true
+	h.started = now
+}
+
+func (h *Health) Stop() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.running = false
+}
+
+// LastSuccess returns the time of the last successful
+// cycle. It is zero, when no cycle succeeded yet.
+func (h *Health) LastSuccess() time.Time {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.lastSuccess
+}
+
+// LastError returns the error of the last cycle,
+// it is nil, when the last cycle succeeded.
+func (h *Health) LastError() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.lastError
+}
+
+// Record stores the result of a cycle.
+func (h *Health) Record(err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err != nil {
+		h.lastError = err
+		return
+	}
+
+	h.lastSuccess = time.Now()
+	h.lastError = nil
+}
+
+// Check returns an error, when the running listener had no
+// successful cycle within the last cycles. Directly after the start,
+// the listener has the same time for its first successful cycle.
+func (h *Health) Check(now time.Time) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.running {
+		return nil
+	}
+
+	last := h.lastSuccess
+
+	if last.Before(h.started) {
+		last = h.started
+	}
+
+	if now.Sub(last) <= missedCycles*h.interval {
+		return nil
+	}
+
+	if h.lastError != nil {
+		return fmt.Errorf("no successful cycle since %v: %w", last.Format(time.RFC3339), h.lastError)
+	}
+
+	return fmt.Errorf("no successful cycle since %v", last.Format(time.RFC3339))
+}
@@ -1,5 +1,14 @@
 package listener
 
+import "context"
+
+// Listener watches the cluster in cycles. It is run by the
+// Supervisor, which restarts it, when it fails.
 type Listener interface {
-	Listen(done <-chan bool) error
+	Name() string
+	// Listen blocks until the context is done or a cycle fails.
+	// Every successful cycle is recorded in the health.
+	Listen(ctx context.Context) error
+	// Health returns the result of the last cycles.
+	Health() *Health
 }
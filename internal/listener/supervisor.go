@@ -0,0 +1,109 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"k8sbot/internal/logging"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	minRestartDelay = 5 * time.Second
+	maxRestartDelay = 5 * time.Minute
+)
+
+// Supervisor runs the listeners in the background and restarts a
+// failed listener with an increasing delay. The delay is reset,
+// when the listener had a successful cycle before it failed.
+type Supervisor struct {
+	listeners []Listener
+	onError   func(err error)
+	logger    *slog.Logger
+	wg        sync.WaitGroup
+}
+
+// NewSupervisor creates a supervisor, which passes the errors
+// of the listeners to onError, e.g. to notify the maintainers.
+func NewSupervisor(listeners []Listener, onError func(err error), logger *slog.Logger) *Supervisor {
+	return &Supervisor{
+		listeners: listeners,
+		onError:   onError,
+		logger:    logger.With(logging.Component, "supervisor"),
+	}
+}
+
+// Start runs every listener until the context is done. It may be
+// called again afterwards, e.g. when the leadership was regained.
+// Then it waits until the listeners of the last run returned.
+func (s *Supervisor) Start(ctx context.Context) {
+	s.wg.Wait()
+
+	for _, l := range s.listeners {
+		s.wg.Add(1)
+
+		go func(l Listener) {
+			defer s.wg.Done()
+			s.run(ctx, l)
+		}(l)
+	}
+}
+
+// Wait blocks until all listeners returned or the context is done.
+func (s *Supervisor) Wait(ctx context.Context) error {
+	stopped := make(chan struct{})
+
+	go func() {
+		s.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("cannot wait for listeners: %w", ctx.Err())
+	case <-stopped:
+		return nil
+	}
+}
+
+func (s *Supervisor) run(ctx context.Context, l Listener) {
+	l.Health().Start(time.Now())
+	defer l.Health().Stop()
+
+	s.logger.Info("started listener", "listener", l.Name())
+	defer s.logger.Info("stopped listener", "listener", l.Name())
+
+	delay := minRestartDelay
+
+	for {
+		started := time.Now()
+		err := l.Listen(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			err = errors.New("stopped unexpectedly")
+		}
+
+		if l.Health().LastSuccess().After(started) {
+			delay = minRestartDelay
+		}
+
+		l.Health().Record(err)
+		s.onError(fmt.Errorf("listener %v failed, restarting in %v: %w", l.Name(), delay, err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxRestartDelay {
+			delay = maxRestartDelay
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Keys of the fields, which every component uses
+// for the same information.
+const (
+	Component = "component"
+	Cluster   = "cluster"
+	ReportID  = "report_id"
+	PostID    = "post_id"
+	SilenceID = "silence_id"
+	Namespace = "namespace"
+	Reason    = "reason"
+	Object    = "object"
+	Notifier  = "notifier"
+	User      = "user"
+	Remote    = "remote"
+	Error     = "error"
+)
+
+// New creates a logger, which writes to the writer. The level can be
+// debug, info, warn or error, the format text or json. Empty values
+// default to info and text.
+func New(writer io.Writer, level, format string) (*slog.Logger, error) {
+	var l slog.Level
+
+	switch strings.ToLower(level) {
+	case "debug":
+		l = slog.LevelDebug
+	case "", "info":
+		l = slog.LevelInfo
+	case "warn":
+		l = slog.LevelWarn
+	case "error":
+		l = slog.LevelError
+	default:
+		return nil, fmt.Errorf("unknown log level %v", level)
+	}
+
+	options := &slog.HandlerOptions{Level: l}
+
+	switch strings.ToLower(format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(writer, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(writer, options)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %v", format)
+	}
+}
+
+// Err returns the error as field.
+func Err(err error) slog.Attr {
+	return slog.String(Error, err.Error())
+}
@@ -0,0 +1,106 @@
+package mattermost
+
+import (
+	"fmt"
+	uuid2 "github.com/golangee/uuid"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"strings"
+)
+
+const (
+	ActionSubmit        = "submit"
+	ActionTake          = "take"
+	ActionSnoozeOneHour = "snooze_1h"
+	ActionSnoozeOneDay  = "snooze_24h"
+	ActionMute          = "mute"
+
+	contextReportID = "report_id"
+	contextAction   = "action"
+	contextExpires  = "expires"
+	contextToken    = "token"
+)
+
+// Action is a verified click on a button of a report.
+type Action struct {
+	ReportID uuid2.UUID
+	Name     string
+	Username string
+}
+
+// actionIntegration creates the integration of a post action.
+// Mattermost sends the context back to the public url of the
+// bot, when a user clicks on the button. The context is signed
+// for the channel of the post, so the bot can verify that the
+// request belongs to the button.
+func (m *MattermostHandler) actionIntegration(reportID uuid2.UUID, action, channelID string) *model.PostActionIntegration {
+	token, expires := m.signer.Sign(reportID.String(), action, channelID)
+
+	return &model.PostActionIntegration{
+		URL: strings.TrimSuffix(m.publicURL, "/") + "/report/action",
+		Context: map[string]interface{}{
+			contextReportID: reportID.String(),
+			contextAction:   action,
+			contextExpires:  expires,
+			contextToken:    token,
+		},
+	}
+}
+
+// ParseAction verifies the integration request of a clicked
+// button and resolves the user by the user-id of the request.
+// When the token of the context is invalid, an InvalidTokenErr
+// is returned. Only members of the channel and maintainers may
+// act on reports, for other users an UnauthorizedUserErr is
+// returned.
+func (m *MattermostHandler) ParseAction(request *model.PostActionIntegrationRequest) (*Action, error) {
+	idStr, _ := request.Context[contextReportID].(string)
+	action, _ := request.Context[contextAction].(string)
+	expires, _ := request.Context[contextExpires].(string)
+	token, _ := request.Context[contextToken].(string)
+
+	if err := m.signer.Verify(idStr, action, request.ChannelId, expires, token); err != nil {
+		return nil, fmt.Errorf("cannot verify action of user %v: %w", request.UserId, err)
+	}
+
+	reportID, err := uuid2.Parse(idStr)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse report id %v: %w", idStr, err)
+	}
+
+	user, resp := m.client.GetUser(request.UserId, "")
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("cannot get user %v: %w", request.UserId, resp.Error)
+	}
+
+	if err := m.authorize(user, request.ChannelId); err != nil {
+		return nil, err
+	}
+
+	return &Action{
+		ReportID: reportID,
+		Name:     action,
+		Username: user.Username,
+	}, nil
+}
+
+// authorize checks, that the user is a maintainer
+// or a member of the channel of the post.
+func (m *MattermostHandler) authorize(user *model.User, channelID string) error {
+	for _, maintainer := range m.maintainerUsernames {
+		if maintainer == user.Username {
+			return nil
+		}
+	}
+
+	_, resp := m.client.GetChannelMember(channelID, user.Id, "")
+
+	if isNotAccessible(resp) {
+		return &UnauthorizedUserErr{Username: user.Username}
+	} else if resp.Error != nil {
+		return fmt.Errorf("cannot get channel member %v: %w", user.Username, resp.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package mattermost
+
+import (
+	"fmt"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"net/http"
+	"sync"
+)
+
+// ChannelResolver resolves the id of the configured channel
+// and caches it, so the team and the channel are not looked
+// up for every post. The cached id is dropped, when mattermost
+// answers a request with not found or forbidden, e.g. because
+// the channel was deleted or the bot was removed from it.
+type ChannelResolver struct {
+	client      *model.Client4
+	teamName    string
+	channelName string
+
+	mutex     sync.Mutex
+	channelID string
+}
+
+func NewChannelResolver(client *model.Client4, teamName, channelName string) *ChannelResolver {
+	return &ChannelResolver{
+		client:      client,
+		teamName:    teamName,
+		channelName: channelName,
+	}
+}
+
+// Validate resolves the channel and returns an error, which
+// explains the misconfiguration, when that is not possible.
+func (c *ChannelResolver) Validate() error {
+	if c.teamName == "" {
+		return fmt.Errorf("team_id must be set to the name of the team")
+	}
+
+	if c.channelName == "" {
+		return fmt.Errorf("dev_ops_channel must be set to the name of the channel")
+	}
+
+	_, err := c.ChannelID()
+
+	return err
+}
+
+// ChannelID returns the cached id of the channel or
+// looks it up, when nothing is cached.
+func (c *ChannelResolver) ChannelID() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.channelID != "" {
+		return c.channelID, nil
+	}
+
+	team, resp := c.client.GetTeamByName(c.teamName, "")
+
+	if isNotAccessible(resp) {
+		return "", fmt.Errorf("team %v does not exist or the bot is no member of it: %w", c.teamName, resp.Error)
+	} else if resp.Error != nil {
+		return "", fmt.Errorf("cannot get team %v: %w", c.teamName, resp.Error)
+	}
+
+	channel, resp := c.client.GetChannelByName(c.channelName, team.Id, "")
+
+	if isNotAccessible(resp) {
+		return "", fmt.Errorf("channel %v does not exist in team %v or the bot is no member of it: %w", c.channelName, c.teamName, resp.Error)
+	} else if resp.Error != nil {
+		return "", fmt.Errorf("cannot get channel %v: %w", c.channelName, resp.Error)
+	}
+
+	c.channelID = channel.Id
+
+	return c.channelID, nil
+}
+
+// Invalidate drops the cached id, when the response shows
+// that the channel cannot be accessed anymore.
+func (c *ChannelResolver) Invalidate(resp *model.Response) {
+	if !isNotAccessible(resp) {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.channelID = ""
+}
+
+func isNotAccessible(resp *model.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden)
+}
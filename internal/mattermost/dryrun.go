@@ -0,0 +1,80 @@
+package mattermost
+
+import (
+	"fmt"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"k8sbot/internal/actiontoken"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/notifier"
+	"k8sbot/internal/reportstorage"
+)
+
+// dryRunNotifier is the name of the DryRunNotifier in the printout.
+const dryRunNotifier = "mattermost"
+
+// DryRunNotifier renders the posts of the reports like the
+// MattermostHandler, but prints their attachments instead
+// of posting them. It needs no connection to mattermost.
+type DryRunNotifier struct {
+	renderer *MattermostHandler
+	printer  *notifier.Printer
+}
+
+func NewDryRunNotifier(publicURL string, signer *actiontoken.Signer, storage reportstorage.ReportStorage, printer *notifier.Printer) *DryRunNotifier {
+	return &DryRunNotifier{
+		renderer: &MattermostHandler{
+			publicURL:     publicURL,
+			signer:        signer,
+			res:           i18n.NewResources("de-DE"),
+			reportStorage: storage,
+		},
+		printer: printer,
+	}
+}
+
+// render returns the attachments of the post of the report.
+func (d *DryRunNotifier) render(report *reportstorage.Report) (interface{}, error) {
+	post := &model.Post{}
+
+	if err := d.renderer.renderPost(report, post); err != nil {
+		return nil, fmt.Errorf("cannot render post: %w", err)
+	}
+
+	return post.GetProp("attachments"), nil
+}
+
+func (d *DryRunNotifier) SendReport(report *reportstorage.Report) error {
+	rendered, err := d.render(report)
+
+	if err != nil {
+		return err
+	}
+
+	return d.printer.PrintSend(dryRunNotifier, report, rendered)
+}
+
+func (d *DryRunNotifier) UpdateReport(report *reportstorage.Report, kind notifier.UpdateKind) error {
+	rendered, err := d.render(report)
+
+	if err != nil {
+		return err
+	}
+
+	return d.printer.PrintUpdate(dryRunNotifier, report, kind, d.renderer.updateText(report, kind), rendered)
+}
+
+func (d *DryRunNotifier) ResolveReport(report *reportstorage.Report) error {
+	rendered, err := d.render(report)
+
+	if err != nil {
+		return err
+	}
+
+	return d.printer.PrintResolve(dryRunNotifier, report, d.renderer.res.FollowupResolved(report.ReportStoppedBy), rendered)
+}
+
+// SendInternalError ignores errors of the printer,
+// because there is nothing left to report them to.
+func (d *DryRunNotifier) SendInternalError(err error) {
+	_ = d.printer.PrintInternalError(dryRunNotifier, err)
+}
@@ -1,299 +1,350 @@
 package mattermost
 
 import (
-	"errors"
 	"fmt"
 	uuid2 "github.com/golangee/uuid"
 	"github.com/mattermost/mattermost-server/v5/model"
-	"k8s.io/apimachinery/pkg/types"
+	"k8sbot/internal/actiontoken"
 	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/notifier"
 	"k8sbot/internal/reportstorage"
-	"log"
+	"log/slog"
 	"path/filepath"
 	"time"
 )
 
+const timeFormat = "15:04:05 02.01.2006"
+
+const (
+	// internalErrorAttempts is the number of tries to post an
+	// internal error, before it is only logged locally.
+	internalErrorAttempts   = 5
+	internalErrorRetryDelay = 2 * time.Second
+)
+
 type MattermostHandler struct {
 	botUser             *model.User
 	client              *model.Client4
 	maintainerUsernames []string
-	devOpsChannelName   string
-	teamId              string
+	channels            *ChannelResolver
+	publicURL           string
+	signer              *actiontoken.Signer
 	res                 i18n.Resources
 	reportStorage       reportstorage.ReportStorage
+	logger              *slog.Logger
 }
 
-func NewMattermostHandler(botUsername string, botUser *model.User, client *model.Client4, maintainerUsernames []string, devOpsChannelName, teamId string, storage reportstorage.ReportStorage) *MattermostHandler {
-	handler := &MattermostHandler{
+func NewMattermostHandler(botUser *model.User, client *model.Client4, maintainerUsernames []string, channels *ChannelResolver, publicURL string, signer *actiontoken.Signer, storage reportstorage.ReportStorage, logger *slog.Logger) *MattermostHandler {
+	return &MattermostHandler{
 		botUser:             botUser,
 		client:              client,
 		maintainerUsernames: maintainerUsernames,
-		devOpsChannelName:   devOpsChannelName,
-		teamId:              teamId,
+		channels:            channels,
+		publicURL:           publicURL,
+		signer:              signer,
 		res:                 i18n.NewResources("de-DE"),
 		reportStorage:       storage,
+		logger:              logger.With(logging.Component, "mattermost"),
 	}
+}
 
-	if err := handler.setupUser(botUsername); err != nil {
-		panic(err)
-	}
+// SendReport creates a new post for the report in the
+// dev-ops channel and remembers its id in the report.
+func (m *MattermostHandler) SendReport(report *reportstorage.Report) error {
+	channelID, err := m.channels.ChannelID()
 
-	return handler
-}
+	if err != nil {
+		return err
+	}
 
-func (m *MattermostHandler) setupUser(username string) error {
-	m.botUser.IsBot = true
-	m.botUser.Username = username
+	post := &model.Post{}
+	post.ChannelId = channelID
 
-	if _, resp := m.client.UpdateUser(m.botUser); resp.Error != nil {
-		return fmt.Errorf("cannot update user: %w", resp.Error)
+	if err := m.renderPost(report, post); err != nil {
+		return err
 	}
 
-	return nil
-}
+	created, err := m.createPost(post)
 
-//TODO: REMOVE REPORT WHEN POST WAS DELETED BY CHAT USER
+	if err != nil {
+		return fmt.Errorf("cannot create post for report: %w", err)
+	}
 
-func (m *MattermostHandler) Listen(done <-chan bool) error {
-	ticker := time.NewTicker(5 * time.Second)
+	report.PostID = created.Id
+	m.logger.Debug("created post", append(report.LogAttrs(), logging.PostID, created.Id)...)
 
-	go func() {
-		for {
-			select {
-			case <-done:
-				ticker.Stop()
-				return
-			case _ = <-ticker.C:
-				if err := m.checkReports(); err != nil {
-					m.SendInternalError(err)
-				}
-			}
-		}
-	}()
 	return nil
 }
 
-func (m *MattermostHandler) checkReports() error {
-	reports, err := m.reportStorage.ReadAll()
+// UpdateReport re-renders the post of the report. Recurring
+// and reopened reports are announced in the thread of the post.
+func (m *MattermostHandler) UpdateReport(report *reportstorage.Report, kind notifier.UpdateKind) error {
+	post, err := m.updatePost(report)
 
 	if err != nil {
-		return fmt.Errorf("cannot read reports: %w", err)
+		return err
 	}
 
-	for _, r := range reports {
-		if !r.ReportStopped {
-			//Check if someone deletes the post
-			// When this ist the case, the report will
-			// be removed from the storage.
-			post, resp := m.client.GetPost(r.PostID, "")
+	if text := m.updateText(report, kind); text != "" {
+		return m.reply(post, text)
+	}
 
-			if resp.StatusCode == 404 {
-				//Post was not found
-				if err := m.reportStorage.Delete(r.ID); err != nil {
-					return fmt.Errorf("cannot remove outdated report: %w", err)
-				}
+	return nil
+}
 
-				continue
-			}
+// updateText returns the reply, which announces the update in the
+// thread of the post. Updates by users aren't announced.
+func (m *MattermostHandler) updateText(report *reportstorage.Report, kind notifier.UpdateKind) string {
+	switch kind {
+	case notifier.Recurred:
+		return notifier.RecurredText(m.res, report)
+	case notifier.Reopened:
+		return m.res.FollowupReopened(int(report.Count))
+	default:
+		return ""
+	}
+}
 
-			if time.Now().After(r.LastReportUpdate.Add(30 * time.Second)) {
-				if err := m.reportStorage.IncreaseCounter(r.ID); err != nil {
-					return fmt.Errorf("cannot increase counter of report: %w", err)
-				}
+// ResolveReport re-renders the post of the submitted
+// report and announces the submission in its thread.
+func (m *MattermostHandler) ResolveReport(report *reportstorage.Report) error {
+	post, err := m.updatePost(report)
 
-				if len(post.Attachments()) != 1 {
-					return fmt.Errorf("got invalid post")
-				}
+	if err != nil {
+		return err
+	}
 
-				attachments := post.Attachments()
+	return m.reply(post, m.res.FollowupResolved(report.ReportStoppedBy))
+}
 
-				for idx, f := range attachments[0].Fields {
-					if f.Title == m.res.CountReportFromBot() {
-						attachments[0].Fields[idx].Value = r.ReportTimes
-					}
-				}
+// Channel returns the dev-ops channel, which all reports are
+// posted to, so the queue limits the rate of the channel.
+func (m *MattermostHandler) Channel(report *reportstorage.Report) string {
+	return m.channels.channelName
+}
 
-				post.AddProp("attachments", attachments)
+// updatePost re-renders the post of the report. Like every request
+// on the dev-ops channel, it drops the cached channel id, when the
+// channel cannot be accessed anymore.
+func (m *MattermostHandler) updatePost(report *reportstorage.Report) (*model.Post, error) {
+	post, resp := m.client.GetPost(report.PostID, "")
 
-				if _, resp := m.client.UpdatePost(post.Id, post); resp.Error != nil {
-					return fmt.Errorf("cannot update post: %w", resp.Error)
-				}
-			}
-		}
+	if resp.Error != nil {
+		m.channels.Invalidate(resp)
+		return nil, fmt.Errorf("cannot get post for report: %w", resp.Error)
 	}
 
-	return nil
+	if err := m.renderPost(report, post); err != nil {
+		return nil, err
+	}
+
+	if _, resp := m.client.UpdatePost(post.Id, post); resp.Error != nil {
+		m.channels.Invalidate(resp)
+		return nil, fmt.Errorf("cannot update post: %w", resp.Error)
+	}
+
+	return post, nil
 }
 
-func (m *MattermostHandler) SendReport(objectID types.UID, namespaces, reason, resource, message, lastTimeStampStr string, count int32) error {
-	var new *reportstorage.Report
-
-	_, err := m.reportStorage.ReadByObjectID(objectID)
-
-	if errors.Is(err, &reportstorage.NoReportErr{}) {
-		new = &reportstorage.Report{
-			ID:               uuid2.New(),
-			ReportedObject:   objectID,
-			Namespace:        namespaces,
-			Reason:           reason,
-			Resource:         resource,
-			Msg:              message,
-			Count:            count,
-			ReportTimes:      1,
-			IsInProgress:     false,
-			LastReportUpdate: time.Now(),
-			ReportStopped:    false,
-			ReportStoppedBy:  "",
-		}
+// renderPost renders the current state of the report
+// as attachment into the given post.
+func (m *MattermostHandler) renderPost(report *reportstorage.Report, post *model.Post) error {
+	mute, err := reportstorage.FindMute(m.reportStorage, time.Now(), report.Namespace, report.Reason)
 
-		team, resp := m.client.GetTeamByName(m.teamId, "")
+	if err != nil {
+		return fmt.Errorf("cannot read mutes: %w", err)
+	}
 
-		if resp.Error != nil {
-			return fmt.Errorf("cannot get team by given name: %w", resp.Error)
-		}
+	fields := []*model.SlackAttachmentField{
+		{
+			Title: m.res.Namespace(),
+			Value: report.Namespace,
+			Short: true,
+		},
+		{
+			Title: m.res.Reason(),
+			Value: report.Reason,
+			Short: true,
+		},
+		{
+			Title: m.res.Object(),
+			Value: report.Resource,
+			Short: true,
+		},
+		{
+			Title: m.res.Message(),
+			Value: report.Msg,
+			Short: true,
+		},
+		{
+			Title: m.res.Count(),
+			Value: report.Count,
+			Short: true,
+		},
+		{
+			Title: m.res.CountReportFromBot(),
+			Value: report.ReportTimes,
+			Short: true,
+		},
+	}
 
-		channel, resp := m.client.GetChannelByName(m.devOpsChannelName, team.Id, "")
+	if report.AssignedTo != "" {
+		fields = append(fields, &model.SlackAttachmentField{
+			Title: m.res.AssignedTo(),
+			Value: report.AssignedTo,
+			Short: true,
+		})
+	}
 
-		if resp.Error != nil {
-			return fmt.Errorf("cannot get channel: %w", err)
-		}
+	if report.IsSnoozed(time.Now()) {
+		fields = append(fields, &model.SlackAttachmentField{
+			Title: m.res.SnoozedUntil(),
+			Value: report.SnoozedUntil.Format(timeFormat),
+			Short: true,
+		})
+	}
 
-		post := &model.Post{}
-		post.ChannelId = channel.Id
+	if mute != nil {
+		fields = append(fields, &model.SlackAttachmentField{
+			Title: m.res.MutedUntil(),
+			Value: fmt.Sprintf("%v (%v)", mute.Until.Format(timeFormat), mute.MutedBy),
+			Short: true,
+		})
+	}
 
-		attachment := []*model.SlackAttachment{{
-			Title: m.res.Warning(),
-			Text:  m.res.UnexpectedEvent(),
-			Fields: []*model.SlackAttachmentField{
-				{
-					Title: m.res.Namespace(),
-					Value: namespaces,
-					Short: true,
-				},
-				{
-					Title: m.res.Reason(),
-					Value: reason,
-					Short: true,
-				},
-				{
-					Title: m.res.Object(),
-					Value: resource,
-					Short: true,
-				},
-				{
-					Title: m.res.Message(),
-					Value: message,
-					Short: true,
-				},
-				{
-					Title: m.res.Count(),
-					Value: count,
-					Short: true,
-				},
-				{
-					Title: m.res.CountReportFromBot(),
-					Value: new.ReportTimes,
-					Short: true,
-				},
+	if report.ReportStopped {
+		fields = append(fields, &model.SlackAttachmentField{
+			Title: m.res.SubmittedAt(),
+			Value: report.ReportStoppedAt.Format(timeFormat),
+			Short: true,
+		}, &model.SlackAttachmentField{
+			Title: m.res.SubmittedBy(),
+			Value: report.ReportStoppedBy,
+			Short: true,
+		})
+	}
+
+	attachment := []*model.SlackAttachment{{
+		Title:  m.res.Warning(),
+		Text:   m.res.UnexpectedEvent(),
+		Fields: fields,
+		Actions: []*model.PostAction{
+			{
+				Type:        model.POST_ACTION_TYPE_BUTTON,
+				Name:        m.res.Submit(),
+				Style:       "success",
+				Integration: m.actionIntegration(report.ID, ActionSubmit, post.ChannelId),
+				Disabled:    report.ReportStopped,
 			},
-			Actions: []*model.PostAction{
-				{
-					Type:  "button",
-					Name:  m.res.Submit(),
-					Style: "success",
-					Integration: &model.PostActionIntegration{
-						//URL: fmt.Sprintf("http://localhost:8065/plugin/%s/report/submit?reportID=%v&user=%v", "net.mspielberger.k8s-bot-redirecter", new.ID.String(), "mspielberger"),
-						URL: fmt.Sprintf("http://192.168.178.20:9090/report/submit?reportID=%v&user=%v", new.ID.String(), "test"),
-					},
-					Disabled: false,
-				},
+			{
+				Type:        model.POST_ACTION_TYPE_BUTTON,
+				Name:        m.res.TakeIt(),
+				Style:       "primary",
+				Integration: m.actionIntegration(report.ID, ActionTake, post.ChannelId),
+				Disabled:    report.ReportStopped || report.IsInProgress,
 			},
-		}}
-
-		model.ParseSlackAttachment(post, attachment)
-
-		if post, resp := m.client.CreatePost(post); resp.Error != nil {
-			return fmt.Errorf("cannot create post for report: %w", resp.Error)
-		} else {
-			new.PostID = post.Id
-			if err := m.reportStorage.Write(new); err != nil {
-				return fmt.Errorf("cannot write report: %w", err)
-			}
-		}
+			{
+				Type:        model.POST_ACTION_TYPE_BUTTON,
+				Name:        m.res.SnoozeOneHour(),
+				Style:       "default",
+				Integration: m.actionIntegration(report.ID, ActionSnoozeOneHour, post.ChannelId),
+				Disabled:    report.ReportStopped,
+			},
+			{
+				Type:        model.POST_ACTION_TYPE_BUTTON,
+				Name:        m.res.SnoozeOneDay(),
+				Style:       "default",
+				Integration: m.actionIntegration(report.ID, ActionSnoozeOneDay, post.ChannelId),
+				Disabled:    report.ReportStopped,
+			},
+			{
+				Type:        model.POST_ACTION_TYPE_BUTTON,
+				Name:        m.res.MuteReasonInNamespace(),
+				Style:       "danger",
+				Integration: m.actionIntegration(report.ID, ActionMute, post.ChannelId),
+				Disabled:    mute != nil,
+			},
+		},
+	}}
 
-		return nil
-	} else if err != nil {
-		return fmt.Errorf("cannot read object: %w", err)
-	}
+	model.ParseSlackAttachment(post, attachment)
 
 	return nil
 }
 
-func (m *MattermostHandler) SubmitReport(reportID uuid2.UUID, username string) error {
+// RenderPost reads the report and its post and returns
+// the post with the current state of the report. The caller
+// is responsible to send the post back to mattermost.
+func (m *MattermostHandler) RenderPost(reportID uuid2.UUID) (*model.Post, error) {
 	report, err := m.reportStorage.ReadByReportID(reportID)
 
 	if err != nil {
-		return fmt.Errorf("cannot read report with given id: %w", err)
+		return nil, fmt.Errorf("cannot read report with given id: %w", err)
 	}
 
 	post, resp := m.client.GetPost(report.PostID, "")
 
 	if resp.Error != nil {
-		return fmt.Errorf("cannot get post for report: %w", resp.Error)
+		m.channels.Invalidate(resp)
+		return nil, fmt.Errorf("cannot get post for report: %w", resp.Error)
 	}
 
-	if len(post.Attachments()) != 1 {
-		return fmt.Errorf("got invalid post")
+	if err := m.renderPost(report, post); err != nil {
+		return nil, err
 	}
 
-	attachments := post.Attachments()
-
-	if len(attachments[0].Actions) != 1 {
-		return fmt.Errorf("got invalid post")
-	}
-
-	attachments[0].Fields = append(attachments[0].Fields, &model.SlackAttachmentField{
-		Title: m.res.SubmittedAt(),
-		Value: time.Now().Format("15:04:05 02.01.2006"),
-		Short: true,
-	})
-
-	attachments[0].Fields = append(attachments[0].Fields, &model.SlackAttachmentField{
-		Title: m.res.SubmittedBy(),
-		Value: username,
-		Short: true,
-	})
-
-	attachments[0].Actions[0].Disabled = true
+	return post, nil
+}
 
-	if err := m.reportStorage.SubmitReport(report.ID, username); err != nil {
-		return fmt.Errorf("cannot update report in storage to submit: %w", err)
+// reply sends the message into the thread of the given post.
+func (m *MattermostHandler) reply(root *model.Post, message string) error {
+	post := &model.Post{
+		ChannelId: root.ChannelId,
+		RootId:    root.Id,
+		Message:   message,
 	}
 
-	model.ParseSlackAttachment(post, attachments)
-
-	if _, resp := m.client.UpdatePost(post.Id, post); resp.Error != nil {
-		return fmt.Errorf("cannot update post: %w", resp.Error)
+	if _, err := m.createPost(post); err != nil {
+		return fmt.Errorf("cannot create reply for post %v: %w", root.Id, err)
 	}
 
 	return nil
 }
 
+// SendInternalError posts the error in the background. When
+// mattermost is not reachable, the post is retried with an
+// increasing delay and finally the error is logged locally,
+// so an outage of mattermost doesn't stop the bot.
 func (m *MattermostHandler) SendInternalError(err error) {
-	team, resp := m.client.GetTeamByName(m.teamId, "")
+	go func() {
+		delay := internalErrorRetryDelay
+		var postErr error
 
-	if resp.Error != nil {
-		log.Fatalln(resp.Error)
-	}
+		for attempt := 0; attempt < internalErrorAttempts; attempt++ {
+			if attempt > 0 {
+				time.Sleep(delay)
+				delay *= 2
+			}
 
-	channel, resp := m.client.GetChannelByName(m.devOpsChannelName, team.Id, "")
+			if postErr = m.postInternalError(err); postErr == nil {
+				return
+			}
+		}
 
-	if resp.Error != nil {
-		log.Fatalln(resp.Error)
+		m.logger.Error("cannot send internal error to mattermost", logging.Err(err), "cause", postErr.Error(), "attempts", internalErrorAttempts)
+	}()
+}
+
+func (m *MattermostHandler) postInternalError(err error) error {
+	channelID, chErr := m.channels.ChannelID()
+
+	if chErr != nil {
+		return chErr
 	}
 
 	post := &model.Post{}
-	post.ChannelId = channel.Id
+	post.ChannelId = channelID
 
 	attachment := []*model.SlackAttachment{{
 		Title:    m.res.InternalError(),
@@ -303,9 +354,11 @@ func (m *MattermostHandler) SendInternalError(err error) {
 
 	model.ParseSlackAttachment(post, attachment)
 
-	if _, resp := m.client.CreatePost(post); resp.Error != nil {
-		log.Fatalln(resp.Error)
+	if _, err := m.createPost(post); err != nil {
+		return fmt.Errorf("cannot create post: %w", err)
 	}
+
+	return nil
 }
 
 func (m *MattermostHandler) SendError(message string) error {
@@ -354,20 +407,14 @@ func (m *MattermostHandler) SendError(message string) error {
 }
 
 func (m *MattermostHandler) SendPodRestartWarning(pod, namespace string, restarts int) error {
-	team, resp := m.client.GetTeamByName(m.teamId, "")
+	channelID, err := m.channels.ChannelID()
 
-	if resp.Error != nil {
-		fmt.Errorf("cannot get team: %w", resp.Error)
-	}
-
-	channel, resp := m.client.GetChannelByName(m.devOpsChannelName, team.Id, "")
-
-	if resp.Error != nil {
-		return fmt.Errorf("cannot get DevOps-channel: %w", resp.Error)
+	if err != nil {
+		return err
 	}
 
 	post := &model.Post{}
-	post.ChannelId = channel.Id
+	post.ChannelId = channelID
 
 	attachment := []*model.SlackAttachment{{
 		Title: m.res.Warning(),
@@ -394,9 +441,23 @@ func (m *MattermostHandler) SendPodRestartWarning(pod, namespace string, restart
 
 	model.ParseSlackAttachment(post, attachment)
 
-	if _, resp := m.client.CreatePost(post); resp.Error != nil {
-		return fmt.Errorf("cannot create post: %w", resp.Error)
+	if _, err := m.createPost(post); err != nil {
+		return fmt.Errorf("cannot create post: %w", err)
 	}
 
 	return nil
 }
+
+// createPost creates the post or reply in the dev-ops channel. When
+// the channel cannot be accessed, its cached id will be dropped, so
+// the next post looks it up again.
+func (m *MattermostHandler) createPost(post *model.Post) (*model.Post, error) {
+	created, resp := m.client.CreatePost(post)
+
+	if resp.Error != nil {
+		m.channels.Invalidate(resp)
+		return nil, resp.Error
+	}
+
+	return created, nil
+}
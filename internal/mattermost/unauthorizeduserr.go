@@ -0,0 +1,12 @@
+package mattermost
+
+// UnauthorizedUserErr is returned, when a user clicks on a button
+// of a report, who is neither a member of the channel of the
+// post nor a maintainer.
+type UnauthorizedUserErr struct {
+	Username string
+}
+
+func (u *UnauthorizedUserErr) Error() string {
+	return "user " + u.Username + " is not allowed to act on the report"
+}
@@ -0,0 +1,235 @@
+package mattermost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reporter"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// submitEmoji is the reaction, that submits a report.
+	submitEmoji = "white_check_mark"
+
+	maxReconnectDelay = time.Minute
+)
+
+// WebSocketListener subscribes to the websocket api of mattermost
+// and reacts to events on the posts of reports:
+//   - a deleted post removes its report
+//   - a ✅ reaction submits the report
+//   - the first reply of a user in the thread takes the report
+type WebSocketListener struct {
+	url      string
+	token    string
+	botUser  *model.User
+	client   *model.Client4
+	storage  reportstorage.ReportStorage
+	reporter *reporter.Reporter
+	logger   *slog.Logger
+}
+
+func NewWebSocketListener(host, token string, botUser *model.User, client *model.Client4, storage reportstorage.ReportStorage, reporter *reporter.Reporter, logger *slog.Logger) *WebSocketListener {
+	url := strings.Replace(host, "http", "ws", 1)
+
+	return &WebSocketListener{
+		url:      url,
+		token:    token,
+		botUser:  botUser,
+		client:   client,
+		storage:  storage,
+		reporter: reporter,
+		logger:   logger.With(logging.Component, "websocket"),
+	}
+}
+
+// Listen connects to the websocket and handles its events in the
+// background. When the connection fails or gets lost, the
+// listener reconnects with an increasing delay.
+func (w *WebSocketListener) Listen(ctx context.Context) error {
+	go func() {
+		ws, appErr := model.NewWebSocketClient4(w.url, w.token)
+
+		if appErr != nil {
+			w.logger.Warn("cannot connect to websocket", logging.Err(appErr))
+			ws = w.reconnect(ctx)
+		}
+
+		for ws != nil {
+			ws.Listen()
+
+			lost := w.consume(ctx, ws)
+			ws.Close()
+
+			if !lost {
+				return
+			}
+
+			w.logger.Warn("lost websocket connection to mattermost", logging.Error, ws.ListenError)
+
+			ws = w.reconnect(ctx)
+		}
+	}()
+
+	return nil
+}
+
+// consume handles the events of the websocket until the
+// connection gets lost or the context is done. It returns true,
+// when the connection was lost.
+func (w *WebSocketListener) consume(ctx context.Context, ws *model.WebSocketClient) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-ws.EventChannel:
+			if !ok {
+				return true
+			}
+
+			if err := w.handleEvent(event); err != nil {
+				w.reporter.SendInternalError(err)
+			}
+		case _, ok := <-ws.ResponseChannel:
+			if !ok {
+				return true
+			}
+		case <-ws.PingTimeoutChannel:
+			return true
+		}
+	}
+}
+
+// reconnect tries to connect to the websocket until it
+// succeeds. When the context is done, nil will be returned.
+func (w *WebSocketListener) reconnect(ctx context.Context) *model.WebSocketClient {
+	delay := time.Second
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		ws, appErr := model.NewWebSocketClient4(w.url, w.token)
+
+		if appErr == nil {
+			return ws
+		}
+
+		w.logger.Warn("cannot reconnect to websocket", logging.Err(appErr))
+
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (w *WebSocketListener) handleEvent(event *model.WebSocketEvent) error {
+	switch event.EventType() {
+	case model.WEBSOCKET_EVENT_POST_DELETED:
+		post := model.PostFromJson(strings.NewReader(eventData(event, "post")))
+
+		if post == nil {
+			return fmt.Errorf("got invalid post_deleted event")
+		}
+
+		report, err := w.readByPostID(post.Id)
+
+		if report == nil {
+			return err
+		}
+
+		return w.reporter.DeleteReport(report.ID)
+	case model.WEBSOCKET_EVENT_REACTION_ADDED:
+		reaction := model.ReactionFromJson(strings.NewReader(eventData(event, "reaction")))
+
+		if reaction == nil {
+			return fmt.Errorf("got invalid reaction_added event")
+		}
+
+		if reaction.UserId == w.botUser.Id || reaction.EmojiName != submitEmoji {
+			return nil
+		}
+
+		report, err := w.readByPostID(reaction.PostId)
+
+		if report == nil || report.ReportStopped {
+			return err
+		}
+
+		username, err := w.username(reaction.UserId)
+
+		if err != nil {
+			return err
+		}
+
+		return w.reporter.SubmitReport(report.ID, username)
+	case model.WEBSOCKET_EVENT_POSTED:
+		post := model.PostFromJson(strings.NewReader(eventData(event, "post")))
+
+		if post == nil {
+			return fmt.Errorf("got invalid posted event")
+		}
+
+		// Only replies of users in the thread of a report take it,
+		// the follow-ups of the bot and system messages don't
+		if post.RootId == "" || post.UserId == w.botUser.Id || post.IsSystemMessage() {
+			return nil
+		}
+
+		report, err := w.readByPostID(post.RootId)
+
+		if report == nil || report.ReportStopped || report.IsInProgress {
+			return err
+		}
+
+		username, err := w.username(post.UserId)
+
+		if err != nil {
+			return err
+		}
+
+		return w.reporter.TakeReport(report.ID, username)
+	default:
+		return nil
+	}
+}
+
+// readByPostID returns the report of the post. When the post
+// doesn't belong to a report, the report and error are nil.
+func (w *WebSocketListener) readByPostID(postID string) (*reportstorage.Report, error) {
+	report, err := w.storage.ReadByPostID(postID)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read report of post %v: %w", postID, err)
+	}
+
+	return report, nil
+}
+
+func (w *WebSocketListener) username(userID string) (string, error) {
+	user, resp := w.client.GetUser(userID, "")
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("cannot get user %v: %w", userID, resp.Error)
+	}
+
+	return user.Username, nil
+}
+
+// eventData returns the json encoded value of the event data.
+func eventData(event *model.WebSocketEvent, key string) string {
+	val, _ := event.GetData()[key].(string)
+
+	return val
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "k8sbot"
+
+var (
+	// EventsProcessed counts the occurrences of warning
+	// events, which the event listener has seen.
+	EventsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_processed_total",
+		Help:      "Occurrences of warning events seen by the event listener.",
+	}, []string{"namespace", "reason"})
+
+	ReportsOpened = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reports_opened_total",
+		Help:      "Reports which were created or reopened.",
+	}, []string{"namespace", "reason"})
+
+	// ReportsAcknowledged counts the reports,
+	// which were submitted by a user.
+	ReportsAcknowledged = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reports_acknowledged_total",
+		Help:      "Reports which were submitted by a user.",
+	}, []string{"namespace", "reason"})
+
+	// ReportsResolved counts the reports, which were submitted
+	// automatically, e.g. by a resolved alert of the alertmanager.
+	ReportsResolved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reports_resolved_total",
+		Help:      "Reports which were submitted automatically.",
+	}, []string{"namespace", "reason"})
+
+	TimeToAcknowledge = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "time_to_acknowledge_seconds",
+		Help:      "Time between the creation of a report and its submission by a user.",
+		Buckets:   []float64{60, 300, 900, 1800, 3600, 7200, 14400, 28800, 86400},
+	}, []string{"namespace"})
+
+	MattermostRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mattermost_request_duration_seconds",
+		Help:      "Latency of the requests to the mattermost api.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// MattermostRequestErrors counts the failed requests to
+	// mattermost by status code. Requests, which didn't get
+	// a response, have the code "error".
+	MattermostRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mattermost_request_errors_total",
+		Help:      "Requests to the mattermost api, which failed.",
+	}, []string{"method", "code"})
+
+	ListenerCycleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "listener_cycle_duration_seconds",
+		Help:      "Duration of a cycle of a listener.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"listener"})
+
+	NotificationsDelivered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Queued notifications by result, which is delivered, failed or dropped.",
+	}, []string{"notifier", "result"})
+
+	// Leader is 1, when the replica watches the cluster
+	// and notifies about reports, otherwise 0.
+	Leader = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "leader",
+		Help:      "Whether this replica is the leader.",
+	})
+)
+
+// ObserveCycle records the duration of a listener
+// cycle, which started at the given time.
+func ObserveCycle(listener string, start time.Time) {
+	ListenerCycleDuration.WithLabelValues(listener).Observe(time.Since(start).Seconds())
+}
+
+// RegisterQueueDepth publishes the depth of the queue
+// of the given notifier.
+func RegisterQueueDepth(notifier string, depth func() float64) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "notification_queue_depth",
+		Help:        "Notifications waiting for delivery.",
+		ConstLabels: prometheus.Labels{"notifier": notifier},
+	}, depth)
+}
+
+// instrumentedTransport records the latency and
+// errors of every request to mattermost.
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+// NewInstrumentedClient returns a http client, which records the
+// requests to the mattermost api. Requests time out, so a hanging
+// mattermost doesn't block the listeners and the readiness probe.
+func NewInstrumentedClient() *http.Client {
+	return &http.Client{
+		Transport: &instrumentedTransport{next: http.DefaultTransport},
+		Timeout:   30 * time.Second,
+	}
+}
+
+func (t *instrumentedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+
+	MattermostRequestDuration.WithLabelValues(request.Method).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		MattermostRequestErrors.WithLabelValues(request.Method, "error").Inc()
+	} else if response.StatusCode >= http.StatusBadRequest {
+		MattermostRequestErrors.WithLabelValues(request.Method, strconv.Itoa(response.StatusCode)).Inc()
+	}
+
+	return response, err
+}
@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"fmt"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// StartsAt and EndsAt are only sent, when they are set, because
+	// the alertmanager resolves an alert with a zero end immediately.
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+}
+
+// AlertmanagerNotifier forwards reports as alerts to the
+// /api/v2/alerts endpoint of a prometheus alertmanager.
+// Updates re-send the alert, so it doesn't get resolved
+// by the resolve timeout of the alertmanager.
+type AlertmanagerNotifier struct {
+	url     string
+	headers map[string]string
+	logger  *slog.Logger
+}
+
+func NewAlertmanagerNotifier(url string, headers map[string]string, logger *slog.Logger) *AlertmanagerNotifier {
+	return &AlertmanagerNotifier{
+		url:     strings.TrimSuffix(url, "/") + "/api/v2/alerts",
+		headers: headers,
+		logger:  logger.With(logging.Component, "alertmanager"),
+	}
+}
+
+func (a *AlertmanagerNotifier) alert(report *reportstorage.Report) *alertmanagerAlert {
+	return &alertmanagerAlert{
+		Labels: map[string]string{
+			"alertname": report.Reason,
+			"namespace": report.Namespace,
+			"reason":    report.Reason,
+			"object":    report.Resource,
+			"severity":  "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("%v %v/%v", report.Reason, report.Namespace, report.Resource),
+			"description": report.Msg,
+			"count":       fmt.Sprintf("%v", report.Count),
+			"report_id":   report.ID.String(),
+		},
+	}
+}
+
+func (a *AlertmanagerNotifier) SendReport(report *reportstorage.Report) error {
+	alert := a.alert(report)
+	now := time.Now()
+	alert.StartsAt = &now
+
+	if err := postJSON(a.url, a.headers, []*alertmanagerAlert{alert}); err != nil {
+		return fmt.Errorf("cannot send alert to alertmanager: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AlertmanagerNotifier) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	if err := postJSON(a.url, a.headers, []*alertmanagerAlert{a.alert(report)}); err != nil {
+		return fmt.Errorf("cannot update alert in alertmanager: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AlertmanagerNotifier) ResolveReport(report *reportstorage.Report) error {
+	alert := a.alert(report)
+	now := time.Now()
+	alert.EndsAt = &now
+
+	if err := postJSON(a.url, a.headers, []*alertmanagerAlert{alert}); err != nil {
+		return fmt.Errorf("cannot resolve alert in alertmanager: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AlertmanagerNotifier) SendInternalError(err error) {
+	now := time.Now()
+	alert := &alertmanagerAlert{
+		Labels: map[string]string{
+			"alertname": "K8sBotInternalError",
+			"severity":  "critical",
+		},
+		Annotations: map[string]string{
+			"description": err.Error(),
+		},
+		StartsAt: &now,
+	}
+
+	if err := postJSON(a.url, a.headers, []*alertmanagerAlert{alert}); err != nil {
+		a.logger.Error("cannot send internal error to alertmanager", logging.Err(err))
+	}
+}
@@ -0,0 +1,69 @@
+package notifier
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// errorRepeatInterval is the time, in which the same
+// internal error is sent only once.
+const errorRepeatInterval = time.Hour
+
+type seenError struct {
+	sentAt     time.Time
+	suppressed int
+}
+
+// errorDeduplicator suppresses internal errors, which were
+// already sent within the interval. A listener which fails
+// every few seconds would flood the channel otherwise.
+type errorDeduplicator struct {
+	interval time.Duration
+
+	mutex sync.Mutex
+	seen  map[string]*seenError
+}
+
+func newErrorDeduplicator(interval time.Duration) *errorDeduplicator {
+	return &errorDeduplicator{
+		interval: interval,
+		seen:     map[string]*seenError{},
+	}
+}
+
+// check returns nil, when the error was already sent within
+// the interval. Otherwise the error is returned, annotated
+// with the number of suppressed repetitions, if there are any.
+func (d *errorDeduplicator) check(err error, now time.Time) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for msg, s := range d.seen {
+		if now.Sub(s.sentAt) >= d.interval && s.suppressed == 0 {
+			delete(d.seen, msg)
+		}
+	}
+
+	s, ok := d.seen[err.Error()]
+
+	if !ok {
+		d.seen[err.Error()] = &seenError{sentAt: now}
+		return err
+	}
+
+	if now.Sub(s.sentAt) < d.interval {
+		s.suppressed++
+		return nil
+	}
+
+	suppressed, since := s.suppressed, s.sentAt
+	s.sentAt = now
+	s.suppressed = 0
+
+	if suppressed == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%w (repeated %v times since %v)", err, suppressed, since.Format("15:04:05"))
+}
@@ -0,0 +1,280 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+var emailTemplate = template.Must(template.New("email").Parse(`<html>
+<body style="font-family: sans-serif;">
+{{range .Entries}}
+<h3>{{.Title}}</h3>
+{{if .Text}}<p>{{.Text}}</p>{{end}}
+{{if .Facts}}
+<table cellpadding="4" style="border-collapse: collapse;">
+{{range .Facts}}<tr><th align="left">{{.Title}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}
+<hr>
+{{end}}
+<p><small>{{.Footer}}</small></p>
+</body>
+</html>
+`))
+
+// emailEntry is a single notification of an email.
+// A digest contains multiple entries.
+type emailEntry struct {
+	Subject string
+	Title   string
+	Text    string
+	Facts   []fact
+}
+
+// EmailNotifier sends reports as emails over smtp. When a digest
+// interval is set, the notifications are collected and sent as
+// one email per interval, otherwise every notification is sent
+// immediately.
+type EmailNotifier struct {
+	addr           string
+	auth           smtp.Auth
+	from           string
+	to             []string
+	digestInterval time.Duration
+	res            i18n.Resources
+	logger         *slog.Logger
+	// sendMail delivers the message, it is smtp.SendMail
+	// and only replaced in tests.
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+	mutex   sync.Mutex
+	pending []*emailEntry
+}
+
+// NewEmailNotifier creates a new email notifier. The username and
+// password are optional, so a local smtp server without
+// authentication can be used.
+func NewEmailNotifier(host string, port int, username, password, from string, to []string, digestInterval time.Duration, logger *slog.Logger) *EmailNotifier {
+	var auth smtp.Auth
+
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &EmailNotifier{
+		addr:           fmt.Sprintf("%v:%v", host, port),
+		auth:           auth,
+		from:           from,
+		to:             to,
+		digestInterval: digestInterval,
+		res:            i18n.NewResources("de-DE"),
+		logger:         logger.With(logging.Component, "email"),
+		sendMail:       smtp.SendMail,
+		pending:        []*emailEntry{},
+	}
+}
+
+// Listen sends the collected digest once per interval.
+// Without digest interval, nothing has to be done.
+func (e *EmailNotifier) Listen(ctx context.Context) error {
+	if e.digestInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(e.digestInterval)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case _ = <-ticker.C:
+				if err := e.Flush(); err != nil {
+					e.logger.Error("cannot send email digest", logging.Err(err))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (e *EmailNotifier) SendReport(report *reportstorage.Report) error {
+	return e.notify(&emailEntry{
+		Subject: e.res.EmailSubjectReport(report.Namespace, report.Resource),
+		Title:   e.res.Warning(),
+		Text:    e.res.UnexpectedEvent(),
+		Facts:   reportFacts(e.res, report),
+	})
+}
+
+func (e *EmailNotifier) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	text := updateText(e.res, report, kind)
+
+	if text == "" {
+		return nil
+	}
+
+	return e.notify(&emailEntry{
+		Subject: e.res.EmailSubjectUpdate(report.Namespace, report.Resource),
+		Title:   e.res.Warning(),
+		Text:    text,
+		Facts:   reportFacts(e.res, report),
+	})
+}
+
+func (e *EmailNotifier) ResolveReport(report *reportstorage.Report) error {
+	return e.notify(&emailEntry{
+		Subject: e.res.EmailSubjectResolved(report.Namespace, report.Resource),
+		Title:   e.res.Info(),
+		Text:    e.res.FollowupResolved(report.ReportStoppedBy),
+		Facts:   reportFacts(e.res, report),
+	})
+}
+
+// SendInternalError sends the error immediately,
+// even when the digest mode is used.
+func (e *EmailNotifier) SendInternalError(err error) {
+	entry := &emailEntry{
+		Subject: e.res.InternalError(),
+		Title:   e.res.InternalError(),
+		Text:    err.Error(),
+	}
+
+	if err := e.send(entry.Subject, []*emailEntry{entry}); err != nil {
+		e.logger.Error("cannot send internal error as email", logging.Err(err))
+	}
+}
+
+func (e *EmailNotifier) notify(entry *emailEntry) error {
+	if e.digestInterval > 0 {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+
+		e.pending = append(e.pending, entry)
+
+		return nil
+	}
+
+	return e.send(entry.Subject, []*emailEntry{entry})
+}
+
+// Flush sends all collected entries as one digest. It is
+// called on shutdown, so the pending entries aren't lost.
+func (e *EmailNotifier) Flush() error {
+	e.mutex.Lock()
+	entries := e.pending
+	e.pending = []*emailEntry{}
+	e.mutex.Unlock()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return e.send(e.res.EmailSubjectDigest(len(entries)), entries)
+}
+
+func (e *EmailNotifier) send(subject string, entries []*emailEntry) error {
+	msg, err := e.message(subject, entries)
+
+	if err != nil {
+		return err
+	}
+
+	if err := e.sendMail(e.addr, e.auth, e.from, e.to, msg); err != nil {
+		return fmt.Errorf("cannot send email: %w", err)
+	}
+
+	return nil
+}
+
+// message renders the entries as multipart email
+// with a plain text and a html alternative.
+func (e *EmailNotifier) message(subject string, entries []*emailEntry) ([]byte, error) {
+	plain := &strings.Builder{}
+
+	for _, entry := range entries {
+		plain.WriteString(entry.Title + "\r\n")
+
+		if entry.Text != "" {
+			plain.WriteString(entry.Text + "\r\n")
+		}
+
+		for _, f := range entry.Facts {
+			plain.WriteString(fmt.Sprintf("%v: %v\r\n", f.Title, f.Value))
+		}
+
+		plain.WriteString("\r\n")
+	}
+
+	plain.WriteString(e.res.EmailFooter() + "\r\n")
+
+	html := &bytes.Buffer{}
+
+	if err := emailTemplate.Execute(html, map[string]interface{}{
+		"Entries": entries,
+		"Footer":  e.res.EmailFooter(),
+	}); err != nil {
+		return nil, fmt.Errorf("cannot render email: %w", err)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{contentType: "text/plain; charset=utf-8", content: plain.String()},
+		{contentType: "text/html; charset=utf-8", content: html.String()},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot create email part: %w", err)
+		}
+
+		qp := quotedprintable.NewWriter(w)
+
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("cannot write email part: %w", err)
+		}
+
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("cannot write email part: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("cannot close email: %w", err)
+	}
+
+	header := &strings.Builder{}
+	header.WriteString("From: " + e.from + "\r\n")
+	header.WriteString("To: " + strings.Join(e.to, ", ") + "\r\n")
+	header.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", "[k8sbot] "+subject) + "\r\n")
+	header.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	header.WriteString("MIME-Version: 1.0\r\n")
+	header.WriteString("Content-Type: multipart/alternative; boundary=" + writer.Boundary() + "\r\n")
+	header.WriteString("\r\n")
+
+	return append([]byte(header.String()), body.Bytes()...), nil
+}
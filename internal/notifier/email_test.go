@@ -0,0 +1,288 @@
+package notifier
+
+import (
+	"bufio"
+	"github.com/golangee/uuid"
+	"io"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStub is a minimal smtp server, which accepts every
+// message and passes its data to the messages channel.
+type smtpStub struct {
+	listener net.Listener
+	messages chan string
+}
+
+func newSmtpStub(t *testing.T) *smtpStub {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub := &smtpStub{
+		listener: listener,
+		messages: make(chan string, 10),
+	}
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go stub.serve()
+
+	return stub
+}
+
+func (s *smtpStub) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) serve() {
+	for {
+		conn, err := s.listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStub) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost")
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "DATA"):
+			reply("354 go ahead")
+
+			data := &strings.Builder{}
+
+			for {
+				line, err := reader.ReadString('\n')
+
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+
+			s.messages <- data.String()
+			reply("250 accepted")
+		case strings.HasPrefix(command, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpStub) receive(t *testing.T) *mail.Message {
+	t.Helper()
+
+	select {
+	case data := <-s.messages:
+		msg, err := mail.ReadMessage(strings.NewReader(data))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+		return nil
+	}
+}
+
+// emailParts returns the decoded parts of the
+// multipart message by their content type.
+func emailParts(t *testing.T, msg *mail.Message) map[string]string {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %v (%v)", mediaType, err)
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	for {
+		part, err := reader.NextRawPart()
+
+		if err == io.EOF {
+			return parts
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(quotedprintable.NewReader(part))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+}
+
+func newTestReport() *reportstorage.Report {
+	return &reportstorage.Report{
+		ID:        uuid.New(),
+		Namespace: "default",
+		Reason:    "BackOff",
+		Resource:  "api-7d9",
+		Msg:       "Back-off restarting failed container",
+		Count:     5,
+	}
+}
+
+func newTestEmailNotifier(port int, digestInterval time.Duration) *EmailNotifier {
+	return NewEmailNotifier("127.0.0.1", port, "", "", "bot@example.com", []string{"ops@example.com"}, digestInterval, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestEmailNotifierSendsMultipartReport(t *testing.T) {
+	stub := newSmtpStub(t)
+	e := newTestEmailNotifier(stub.port(), 0)
+
+	if err := e.SendReport(newTestReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := stub.receive(t)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "[k8sbot] " + e.res.EmailSubjectReport("default", "api-7d9"); subject != want {
+		t.Errorf("expected subject %q, got %q", want, subject)
+	}
+
+	parts := emailParts(t, msg)
+
+	for _, contentType := range []string{"text/plain", "text/html"} {
+		if !strings.Contains(parts[contentType], "Back-off restarting failed container") {
+			t.Errorf("expected the message in the %v part, got %q", contentType, parts[contentType])
+		}
+
+		if !strings.Contains(parts[contentType], e.res.EmailFooter()) {
+			t.Errorf("expected the footer in the %v part", contentType)
+		}
+	}
+
+	if !strings.Contains(parts["text/html"], "<h3>"+e.res.Warning()+"</h3>") {
+		t.Errorf("expected the title as heading, got %q", parts["text/html"])
+	}
+}
+
+func TestEmailNotifierCollectsDigest(t *testing.T) {
+	stub := newSmtpStub(t)
+	e := newTestEmailNotifier(stub.port(), time.Hour)
+	first, second := newTestReport(), newTestReport()
+	second.Resource = "worker-5f2"
+
+	for _, report := range []*reportstorage.Report{first, second} {
+		if err := e.SendReport(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-stub.messages:
+		t.Fatal("expected no email before the digest is flushed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := stub.receive(t)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+
+	if want := "[k8sbot] " + e.res.EmailSubjectDigest(2); subject != want {
+		t.Errorf("expected subject %q, got %q", want, subject)
+	}
+
+	plain := emailParts(t, msg)["text/plain"]
+
+	for _, resource := range []string{"api-7d9", "worker-5f2"} {
+		if !strings.Contains(plain, resource) {
+			t.Errorf("expected %v in the digest, got %q", resource, plain)
+		}
+	}
+
+	// An empty digest isn't sent
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-stub.messages:
+		t.Fatal("expected no email for an empty digest")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEmailNotifierSendsInternalErrorDespiteDigest(t *testing.T) {
+	e := newTestEmailNotifier(25, time.Hour)
+	sent := []string{}
+	e.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		if addr != "127.0.0.1:25" {
+			t.Errorf("unexpected address %v", addr)
+		}
+
+		sent = append(sent, string(msg))
+
+		return nil
+	}
+
+	e.SendInternalError(io.ErrUnexpectedEOF)
+
+	if len(sent) != 1 || !strings.Contains(sent[0], io.ErrUnexpectedEOF.Error()) {
+		t.Fatalf("expected the internal error to be sent immediately, got %q", sent)
+	}
+}
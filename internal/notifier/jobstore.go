@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"github.com/golangee/uuid"
+	"sync"
+	"time"
+)
+
+// JobKind describes which method of the notifier delivers a job.
+type JobKind string
+
+const (
+	SendJob    JobKind = "send"
+	UpdateJob  JobKind = "update"
+	ResolveJob JobKind = "resolve"
+)
+
+// Job is a queued notification about a report. It only
+// references the report, so the report is read with its
+// current state, when the job gets delivered. The notified
+// count is kept from the time the job was queued, because the
+// reporter updates it in the storage right after queueing.
+// The channel is the key of the rate limit and the post id
+// is set, once a send job created the post of the report.
+type Job struct {
+	ID            uuid.UUID  `json:"id"`
+	Kind          JobKind    `json:"kind"`
+	ReportID      uuid.UUID  `json:"report_id"`
+	Channel       string     `json:"channel"`
+	Update        UpdateKind `json:"update"`
+	NotifiedCount int32      `json:"notified_count"`
+	PostID        string     `json:"post_id"`
+	Attempts      int        `json:"attempts"`
+	NextAttempt   time.Time  `json:"next_attempt"`
+}
+
+// JobStore keeps the jobs of a queue in order. Implementations
+// can persist the jobs, so they survive a restart of the bot.
+type JobStore interface {
+	// Push appends the job to the end of the queue.
+	Push(job *Job) error
+	// List returns the jobs in order.
+	List() ([]*Job, error)
+	// Update stores the changed attempts of the job.
+	Update(job *Job) error
+	// Remove deletes the job from the queue.
+	Remove(jobID uuid.UUID) error
+	Len() (int, error)
+}
+
+// InMemoryJobStore keeps the jobs only in memory. Pending jobs
+// are lost, when the bot restarts, so the notifications are
+// delivered at most once across restarts.
+type InMemoryJobStore struct {
+	mutex sync.Mutex
+	jobs  []*Job
+}
+
+func NewInMemoryJobStore() *InMemoryJobStore {
+	return &InMemoryJobStore{
+		jobs: []*Job{},
+	}
+}
+
+func (i *InMemoryJobStore) Push(job *Job) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.jobs = append(i.jobs, job)
+
+	return nil
+}
+
+func (i *InMemoryJobStore) List() ([]*Job, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	jobs := make([]*Job, 0, len(i.jobs))
+
+	for _, j := range i.jobs {
+		job := *j
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+func (i *InMemoryJobStore) Update(job *Job) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for n, j := range i.jobs {
+		if j.ID == job.ID {
+			updated := *job
+			i.jobs[n] = &updated
+		}
+	}
+
+	return nil
+}
+
+func (i *InMemoryJobStore) Remove(jobID uuid.UUID) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	tmp := []*Job{}
+
+	for _, j := range i.jobs {
+		if j.ID != jobID {
+			tmp = append(tmp, j)
+		}
+	}
+
+	i.jobs = tmp
+
+	return nil
+}
+
+func (i *InMemoryJobStore) Len() (int, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return len(i.jobs), nil
+}
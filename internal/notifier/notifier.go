@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"fmt"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/reportstorage"
+)
+
+// UpdateKind describes why a report was updated.
+type UpdateKind int
+
+const (
+	// StateChanged is used, when a user changed the report,
+	// e.g. by taking or snoozing it.
+	StateChanged UpdateKind = iota
+	// Recurred is used, when the event of an open report
+	// happened again since the last notification.
+	Recurred
+	// Reopened is used, when the event of a submitted
+	// report happened again.
+	Reopened
+)
+
+// Notifier delivers the lifecycle of reports to a chat or any
+// other sink. SendReport may set sink specific references like
+// the PostID on the report, the Queue stores them afterwards.
+type Notifier interface {
+	SendReport(report *reportstorage.Report) error
+	UpdateReport(report *reportstorage.Report, kind UpdateKind) error
+	ResolveReport(report *reportstorage.Report) error
+	SendInternalError(err error)
+}
+
+// ChannelNotifier is implemented by notifiers, which know the
+// channel a report is delivered to. The Queue limits the rate
+// per channel, other notifiers count as a single channel.
+type ChannelNotifier interface {
+	Channel(report *reportstorage.Report) string
+}
+
+// fact is a single key-value pair of a rendered report.
+type fact struct {
+	Title string
+	Value string
+}
+
+// reportFacts returns the facts, that every sink shows for a report.
+func reportFacts(res i18n.Resources, report *reportstorage.Report) []fact {
+	return []fact{
+		{Title: res.Namespace(), Value: report.Namespace},
+		{Title: res.Reason(), Value: report.Reason},
+		{Title: res.Object(), Value: report.Resource},
+		{Title: res.Message(), Value: report.Msg},
+		{Title: res.Count(), Value: fmt.Sprintf("%v", report.Count)},
+	}
+}
+
+// updateText returns the text, that describes the given update.
+// For StateChanged no text exists, because sinks without the
+// possibility to edit a message have nothing to tell about it.
+func updateText(res i18n.Resources, report *reportstorage.Report, kind UpdateKind) string {
+	switch kind {
+	case Recurred:
+		return RecurredText(res, report)
+	case Reopened:
+		return res.FollowupReopened(int(report.Count))
+	default:
+		return ""
+	}
+}
+
+// RecurredText describes how often the event happened since the
+// last notification. It has to be called before the notified
+// count of the report is updated.
+func RecurredText(res i18n.Resources, report *reportstorage.Report) string {
+	// The count at least doubled since the last notification
+	if report.Count >= 2*report.NotifiedCount {
+		return res.FollowupCountJump(int(report.NotifiedCount), int(report.Count))
+	}
+
+	return res.FollowupOccurredAgain(int(report.Count), int(report.Count-report.NotifiedCount))
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON sends the payload as json to the given url.
+// Every status code outside of 2xx is treated as error.
+func postJSON(url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return fmt.Errorf("cannot marshal payload: %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+
+	if err != nil {
+		return fmt.Errorf("cannot create request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	for key, val := range headers {
+		request.Header.Set(key, val)
+	}
+
+	response, err := httpClient.Do(request)
+
+	if err != nil {
+		return fmt.Errorf("cannot send request: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+
+		return fmt.Errorf("webhook responded with %v: %v", response.Status, string(msg))
+	}
+
+	return nil
+}
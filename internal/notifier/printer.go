@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/reportstorage"
+	"sync"
+	"time"
+)
+
+// printout is a notification, which was printed instead of sent.
+// Rendered is the sink specific representation of the report.
+type printout struct {
+	Notifier string      `json:"notifier"`
+	Event    string      `json:"event"`
+	Time     time.Time   `json:"time"`
+	ReportID string      `json:"report_id,omitempty"`
+	Text     string      `json:"text,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Rendered interface{} `json:"rendered,omitempty"`
+}
+
+// Printer writes notifications as json lines instead of sending them,
+// e.g. in dry-run mode. The notifiers of a dry run share one printer,
+// so their lines don't interleave. The events are named like the
+// events of the WebhookNotifier.
+type Printer struct {
+	writer io.Writer
+	mutex  sync.Mutex
+	// now returns the time of the notifications, see SetClock.
+	now func() time.Time
+}
+
+func NewPrinter(writer io.Writer) *Printer {
+	return &Printer{
+		writer: writer,
+		now:    time.Now,
+	}
+}
+
+// SetClock replaces the clock of the printer, so the notifications
+// of replayed events have the time they would have been sent.
+func (p *Printer) SetClock(now func() time.Time) {
+	p.now = now
+}
+
+func (p *Printer) PrintSend(notifier string, report *reportstorage.Report, rendered interface{}) error {
+	return p.print(&printout{
+		Notifier: notifier,
+		Event:    webhookEventReportCreated,
+		ReportID: report.ID.String(),
+		Rendered: rendered,
+	})
+}
+
+// PrintUpdate prints the update of the report. The text is
+// the message, which announces the update, it may be empty.
+func (p *Printer) PrintUpdate(notifier string, report *reportstorage.Report, kind UpdateKind, text string, rendered interface{}) error {
+	event := webhookEventReportUpdated
+
+	if kind == Reopened {
+		event = webhookEventReportReopened
+	}
+
+	return p.print(&printout{
+		Notifier: notifier,
+		Event:    event,
+		ReportID: report.ID.String(),
+		Text:     text,
+		Rendered: rendered,
+	})
+}
+
+func (p *Printer) PrintResolve(notifier string, report *reportstorage.Report, text string, rendered interface{}) error {
+	return p.print(&printout{
+		Notifier: notifier,
+		Event:    webhookEventReportResolved,
+		ReportID: report.ID.String(),
+		Text:     text,
+		Rendered: rendered,
+	})
+}
+
+func (p *Printer) PrintInternalError(notifier string, err error) error {
+	return p.print(&printout{
+		Notifier: notifier,
+		Event:    webhookEventInternalError,
+		Error:    err.Error(),
+	})
+}
+
+func (p *Printer) print(out *printout) error {
+	out.Time = p.now()
+
+	line, err := json.Marshal(out)
+
+	if err != nil {
+		return fmt.Errorf("cannot marshal notification: %w", err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, err := p.writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("cannot print notification: %w", err)
+	}
+
+	return nil
+}
+
+// PrintNotifier prints the notifications of a sink with the
+// generic representation of the WebhookNotifier.
+type PrintNotifier struct {
+	name    string
+	printer *Printer
+	res     i18n.Resources
+}
+
+func NewPrintNotifier(name string, printer *Printer) *PrintNotifier {
+	return &PrintNotifier{
+		name:    name,
+		printer: printer,
+		res:     i18n.NewResources("de-DE"),
+	}
+}
+
+func (p *PrintNotifier) SendReport(report *reportstorage.Report) error {
+	return p.printer.PrintSend(p.name, report, newWebhookReport(report))
+}
+
+func (p *PrintNotifier) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	return p.printer.PrintUpdate(p.name, report, kind, updateText(p.res, report, kind), newWebhookReport(report))
+}
+
+func (p *PrintNotifier) ResolveReport(report *reportstorage.Report) error {
+	return p.printer.PrintResolve(p.name, report, p.res.FollowupResolved(report.ReportStoppedBy), newWebhookReport(report))
+}
+
+// SendInternalError ignores errors of the printer,
+// because there is nothing left to report them to.
+func (p *PrintNotifier) SendInternalError(err error) {
+	_ = p.printer.PrintInternalError(p.name, err)
+}
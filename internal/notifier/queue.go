@@ -0,0 +1,321 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golangee/uuid"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/metrics"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"time"
+)
+
+const (
+	// maxJobAttempts is the number of tries to deliver a
+	// job, before it gets dropped.
+	maxJobAttempts = 10
+	minJobBackoff  = 5 * time.Second
+	maxJobBackoff  = 10 * time.Minute
+	// idleInterval is the time, an empty queue waits
+	// before it checks the job store again.
+	idleInterval = time.Minute
+)
+
+// Queue is a notifier, which stores every notification as job
+// and delivers it to the wrapped notifier in the background. The
+// jobs are delivered in order with at most ratePerMinute jobs per
+// minute and channel, see ChannelNotifier. A failed job is retried
+// with an increasing delay and only blocks the following jobs of
+// the same report, so the order of the notifications about a
+// report is kept, while the other reports are still delivered.
+type Queue struct {
+	name     string
+	notifier Notifier
+	jobs     JobStore
+	storage  reportstorage.ReportStorage
+	interval time.Duration
+	logger   *slog.Logger
+	wake     chan struct{}
+	// stopped is closed, when the listening goroutine returned.
+	stopped chan struct{}
+
+	// lastDelivery is the time of the last delivery by channel.
+	lastDelivery map[string]time.Time
+}
+
+func NewQueue(name string, notifier Notifier, jobs JobStore, storage reportstorage.ReportStorage, ratePerMinute int, logger *slog.Logger) *Queue {
+	q := &Queue{
+		name:     name,
+		notifier: notifier,
+		jobs:     jobs,
+		storage:  storage,
+		interval: time.Minute / time.Duration(ratePerMinute),
+		logger:   logger.With(logging.Component, "queue", logging.Notifier, name),
+		wake:     make(chan struct{}, 1),
+		stopped:  make(chan struct{}),
+
+		lastDelivery: map[string]time.Time{},
+	}
+
+	metrics.RegisterQueueDepth(name, func() float64 {
+		depth, _ := q.jobs.Len()
+		return float64(depth)
+	})
+
+	return q
+}
+
+// Listen delivers the queued jobs until the context is done.
+func (q *Queue) Listen(ctx context.Context) error {
+	go func() {
+		defer close(q.stopped)
+
+		for {
+			wait := q.process(time.Now())
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+			case <-time.After(wait):
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Drain waits until the queue stopped listening and delivers the
+// remaining jobs until the context is done. The rate limit and
+// the retry delays still apply.
+func (q *Queue) Drain(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("cannot drain queue %v: %w", q.name, ctx.Err())
+	case <-q.stopped:
+	}
+
+	for {
+		depth, err := q.jobs.Len()
+
+		if err != nil {
+			return fmt.Errorf("cannot read depth of queue %v: %w", q.name, err)
+		}
+
+		if depth == 0 {
+			return nil
+		}
+
+		wait := q.process(time.Now())
+
+		if wait == 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("cannot deliver %v notifications for %v: %w", depth, q.name, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (q *Queue) SendReport(report *reportstorage.Report) error {
+	return q.push(SendJob, report, StateChanged)
+}
+
+func (q *Queue) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	return q.push(UpdateJob, report, kind)
+}
+
+func (q *Queue) ResolveReport(report *reportstorage.Report) error {
+	return q.push(ResolveJob, report, StateChanged)
+}
+
+// SendInternalError isn't queued, because internal errors
+// are often caused by the sink, the queue delivers to.
+func (q *Queue) SendInternalError(err error) {
+	q.notifier.SendInternalError(err)
+}
+
+func (q *Queue) push(kind JobKind, report *reportstorage.Report, update UpdateKind) error {
+	if err := q.jobs.Push(&Job{
+		ID:            uuid.New(),
+		Kind:          kind,
+		ReportID:      report.ID,
+		Channel:       q.channel(report),
+		Update:        update,
+		NotifiedCount: report.NotifiedCount,
+	}); err != nil {
+		return fmt.Errorf("cannot queue notification for %v: %w", q.name, err)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// channel returns the channel, the report is delivered to.
+// Without ChannelNotifier, the whole sink is one channel.
+func (q *Queue) channel(report *reportstorage.Report) string {
+	if n, ok := q.notifier.(ChannelNotifier); ok {
+		return n.Channel(report)
+	}
+
+	return q.name
+}
+
+// process delivers the first due job. Jobs, whose channel exceeds
+// the rate limit or which are behind a job of the same report,
+// that waits for its retry, aren't due. It returns the time to
+// wait for the next job.
+func (q *Queue) process(now time.Time) time.Duration {
+	job, wait, err := q.next(now)
+
+	if err != nil {
+		q.logger.Error("cannot read jobs", logging.Err(err))
+		return idleInterval
+	}
+
+	if job == nil {
+		return wait
+	}
+
+	q.lastDelivery[job.Channel] = now
+
+	if err := q.deliver(job); err != nil {
+		metrics.NotificationsDelivered.WithLabelValues(q.name, "failed").Inc()
+		job.Attempts++
+
+		if job.Attempts >= maxJobAttempts {
+			q.logger.Error("dropped notification", "job", job.Kind, logging.ReportID, job.ReportID.String(), "attempts", job.Attempts, logging.Err(err))
+			metrics.NotificationsDelivered.WithLabelValues(q.name, "dropped").Inc()
+
+			return q.remove(job)
+		}
+
+		job.NextAttempt = now.Add(backoff(job.Attempts))
+
+		if err := q.jobs.Update(job); err != nil {
+			q.logger.Error("cannot update job", logging.Err(err))
+		}
+
+		return 0
+	}
+
+	metrics.NotificationsDelivered.WithLabelValues(q.name, "delivered").Inc()
+
+	return q.remove(job)
+}
+
+// next returns the first due job. When no job is due, the time
+// until the next retry or free slot of a channel is returned.
+func (q *Queue) next(now time.Time) (*Job, time.Duration, error) {
+	jobs, err := q.jobs.List()
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	wait := idleInterval
+	blocked := map[uuid.UUID]bool{}
+
+	for _, job := range jobs {
+		if blocked[job.ReportID] {
+			continue
+		}
+
+		due := job.NextAttempt
+
+		if slot := q.lastDelivery[job.Channel].Add(q.interval); slot.After(due) {
+			due = slot
+		}
+
+		if !due.After(now) {
+			return job, 0, nil
+		}
+
+		blocked[job.ReportID] = true
+
+		if d := due.Sub(now); d < wait {
+			wait = d
+		}
+	}
+
+	return nil, wait, nil
+}
+
+func (q *Queue) remove(job *Job) time.Duration {
+	if err := q.jobs.Remove(job.ID); err != nil {
+		q.logger.Error("cannot remove job", logging.Err(err))
+		return idleInterval
+	}
+
+	return 0
+}
+
+// deliver reads the current state of the report and passes it to
+// the notifier. Jobs of deleted reports are dropped silently. The
+// post id of a sent report is kept in the job, before it is stored
+// in the report, so a retry only stores it and doesn't send the
+// report a second time.
+func (q *Queue) deliver(job *Job) error {
+	stored, err := q.storage.ReadByReportID(job.ReportID)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read report: %w", err)
+	}
+
+	report := *stored
+
+	switch job.Kind {
+	case SendJob:
+		if job.PostID == "" {
+			if err := q.notifier.SendReport(&report); err != nil {
+				return err
+			}
+
+			job.PostID = report.PostID
+
+			if err := q.jobs.Update(job); err != nil {
+				return fmt.Errorf("cannot update job: %w", err)
+			}
+		}
+
+		if job.PostID != stored.PostID {
+			return q.storage.SetPostID(report.ID, job.PostID)
+		}
+
+		return nil
+	case UpdateJob:
+		report.NotifiedCount = job.NotifiedCount
+
+		return q.notifier.UpdateReport(&report, job.Update)
+	case ResolveJob:
+		return q.notifier.ResolveReport(&report)
+	default:
+		return fmt.Errorf("unknown job kind %v", job.Kind)
+	}
+}
+
+// backoff returns the delay before the given attempt.
+func backoff(attempts int) time.Duration {
+	delay := minJobBackoff
+
+	for i := 1; i < attempts && delay < maxJobBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxJobBackoff {
+		return maxJobBackoff
+	}
+
+	return delay
+}
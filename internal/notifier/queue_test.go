@@ -0,0 +1,246 @@
+package notifier
+
+import (
+	"errors"
+	"github.com/golangee/uuid"
+	"io"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// recordingNotifier records the texts of the updates and
+// fails for the reports in failing.
+type recordingNotifier struct {
+	res     i18n.Resources
+	failing map[uuid.UUID]bool
+	sent    []uuid.UUID
+	texts   []string
+}
+
+func newRecordingNotifier() *recordingNotifier {
+	return &recordingNotifier{
+		res:     i18n.NewResources("de-DE"),
+		failing: map[uuid.UUID]bool{},
+	}
+}
+
+func (r *recordingNotifier) SendReport(report *reportstorage.Report) error {
+	if r.failing[report.ID] {
+		return errors.New("sink unavailable")
+	}
+
+	r.sent = append(r.sent, report.ID)
+	report.PostID = "post-" + report.ID.String()
+
+	return nil
+}
+
+func (r *recordingNotifier) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	if r.failing[report.ID] {
+		return errors.New("sink unavailable")
+	}
+
+	r.texts = append(r.texts, updateText(r.res, report, kind))
+
+	return nil
+}
+
+func (r *recordingNotifier) ResolveReport(report *reportstorage.Report) error {
+	return nil
+}
+
+func (r *recordingNotifier) SendInternalError(err error) {
+}
+
+func newTestQueue(t *testing.T, n Notifier, storage reportstorage.ReportStorage) *Queue {
+	t.Helper()
+
+	// The name is unique, because the depth of every queue is registered as metric
+	return NewQueue(t.Name()+"-"+uuid.New().String(), n, NewInMemoryJobStore(), storage, 60000, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func writeTestReport(t *testing.T, storage reportstorage.ReportStorage, count, notifiedCount int32) *reportstorage.Report {
+	t.Helper()
+
+	report := &reportstorage.Report{
+		ID:             uuid.New(),
+		ReportedObject: "uid",
+		Namespace:      "default",
+		Reason:         "BackOff",
+		Count:          count,
+		NotifiedCount:  notifiedCount,
+	}
+
+	if err := storage.Write(report); err != nil {
+		t.Fatal(err)
+	}
+
+	return report
+}
+
+func TestQueueRendersDeltaOfRecurrence(t *testing.T) {
+	storage := reportstorage.NewInMemoryReportStorage()
+	n := newRecordingNotifier()
+	q := newTestQueue(t, n, storage)
+	report := writeTestReport(t, storage, 5, 4)
+
+	if err := q.UpdateReport(report, Recurred); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reporter updates the notified count right after queueing
+	if err := storage.SetNotified(report.ID, 5, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	q.process(time.Now())
+
+	want := n.res.FollowupOccurredAgain(5, 1)
+
+	if len(n.texts) != 1 || n.texts[0] != want {
+		t.Fatalf("expected %q, got %q", want, n.texts)
+	}
+}
+
+func TestQueueDeliversOtherReportsWhileRetrying(t *testing.T) {
+	storage := reportstorage.NewInMemoryReportStorage()
+	n := newRecordingNotifier()
+	q := newTestQueue(t, n, storage)
+	failing := writeTestReport(t, storage, 1, 1)
+	other := writeTestReport(t, storage, 1, 1)
+	n.failing[failing.ID] = true
+
+	for _, report := range []*reportstorage.Report{failing, other} {
+		if err := q.SendReport(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+
+	// The first attempt fails, the second delivers the other report
+	q.process(now)
+	q.process(now.Add(time.Second))
+
+	if len(n.sent) != 1 || n.sent[0] != other.ID {
+		t.Fatalf("expected the other report to be delivered, got %v", n.sent)
+	}
+
+	// The update of the failing report waits behind its send
+	if err := q.UpdateReport(failing, Recurred); err != nil {
+		t.Fatal(err)
+	}
+
+	n.failing[failing.ID] = false
+
+	if wait := q.process(now.Add(2 * time.Second)); wait <= 0 {
+		t.Fatalf("expected to wait for the retry, got %v", wait)
+	}
+
+	q.process(now.Add(time.Minute))
+	q.process(now.Add(time.Minute + time.Second))
+
+	if len(n.sent) != 2 || n.sent[1] != failing.ID || len(n.texts) != 1 {
+		t.Fatalf("expected the send before the update, got %v and %q", n.sent, n.texts)
+	}
+}
+
+// namespaceNotifier delivers the reports of every namespace to its own channel.
+type namespaceNotifier struct {
+	*recordingNotifier
+}
+
+func (n namespaceNotifier) Channel(report *reportstorage.Report) string {
+	return report.Namespace
+}
+
+func TestQueueLimitsRatePerChannel(t *testing.T) {
+	storage := reportstorage.NewInMemoryReportStorage()
+	n := newRecordingNotifier()
+	q := NewQueue(t.Name()+"-"+uuid.New().String(), namespaceNotifier{n}, NewInMemoryJobStore(), storage, 1, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	first := writeTestReport(t, storage, 1, 1)
+	second := writeTestReport(t, storage, 1, 1)
+	other := &reportstorage.Report{ID: uuid.New(), Namespace: "monitoring", Count: 1, NotifiedCount: 1}
+
+	if err := storage.Write(other); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, report := range []*reportstorage.Report{first, second, other} {
+		if err := q.SendReport(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+
+	// The noisy channel doesn't delay the other channel
+	q.process(now)
+	q.process(now.Add(time.Second))
+
+	if len(n.sent) != 2 || n.sent[0] != first.ID || n.sent[1] != other.ID {
+		t.Fatalf("expected the first report of each channel, got %v", n.sent)
+	}
+
+	if wait := q.process(now.Add(2 * time.Second)); wait != time.Minute-2*time.Second {
+		t.Fatalf("expected to wait for the next slot of the channel, got %v", wait)
+	}
+
+	q.process(now.Add(time.Minute))
+
+	if len(n.sent) != 3 || n.sent[2] != second.ID {
+		t.Fatalf("expected the second report after a minute, got %v", n.sent)
+	}
+}
+
+// flakyStorage fails to store the first post id.
+type flakyStorage struct {
+	*reportstorage.InMemoryReportStorage
+	failed bool
+}
+
+func (f *flakyStorage) SetPostID(reportID uuid.UUID, postID string) error {
+	if !f.failed {
+		f.failed = true
+		return errors.New("storage unavailable")
+	}
+
+	return f.InMemoryReportStorage.SetPostID(reportID, postID)
+}
+
+func TestQueueDoesNotResendAfterFailedStorageWrite(t *testing.T) {
+	storage := &flakyStorage{InMemoryReportStorage: reportstorage.NewInMemoryReportStorage()}
+	n := newRecordingNotifier()
+	q := newTestQueue(t, n, storage)
+	report := writeTestReport(t, storage, 1, 1)
+
+	if err := q.SendReport(report); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	q.process(now)
+	q.process(now.Add(time.Minute))
+
+	if len(n.sent) != 1 {
+		t.Fatalf("expected the report to be sent once, got %v", n.sent)
+	}
+
+	stored, err := storage.ReadByReportID(report.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "post-" + report.ID.String(); stored.PostID != want {
+		t.Fatalf("expected post id %v, got %v", want, stored.PostID)
+	}
+
+	if depth, _ := q.jobs.Len(); depth != 0 {
+		t.Fatalf("expected an empty queue, got %v jobs", depth)
+	}
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"k8sbot/internal/reportstorage"
+	"time"
+)
+
+// Route sends every report, that matches the namespaces
+// and reasons, to the notifier. An empty list matches
+// everything.
+type Route struct {
+	Namespaces []string
+	Reasons    []string
+	Notifier   Notifier
+}
+
+func (r *Route) matches(report *reportstorage.Report) bool {
+	return contains(r.Namespaces, report.Namespace) && contains(r.Reasons, report.Reason)
+}
+
+func contains(list []string, val string) bool {
+	if len(list) == 0 {
+		return true
+	}
+
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Router is a notifier, that forwards every report to the
+// notifier of the first matching route. When no route matches,
+// the fallback is used. Internal errors always go to the fallback,
+// repeating errors are only sent once per hour.
+type Router struct {
+	fallback Notifier
+	routes   []*Route
+	errors   *errorDeduplicator
+}
+
+func NewRouter(fallback Notifier, routes []*Route) *Router {
+	return &Router{
+		fallback: fallback,
+		routes:   routes,
+		errors:   newErrorDeduplicator(errorRepeatInterval),
+	}
+}
+
+func (r *Router) route(report *reportstorage.Report) Notifier {
+	for _, route := range r.routes {
+		if route.matches(report) {
+			return route.Notifier
+		}
+	}
+
+	return r.fallback
+}
+
+func (r *Router) SendReport(report *reportstorage.Report) error {
+	return r.route(report).SendReport(report)
+}
+
+func (r *Router) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	return r.route(report).UpdateReport(report, kind)
+}
+
+func (r *Router) ResolveReport(report *reportstorage.Report) error {
+	return r.route(report).ResolveReport(report)
+}
+
+func (r *Router) SendInternalError(err error) {
+	if err = r.errors.check(err, time.Now()); err != nil {
+		r.fallback.SendInternalError(err)
+	}
+}
@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"fmt"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+)
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string        `json:"color,omitempty"`
+	Title  string        `json:"title"`
+	Text   string        `json:"text,omitempty"`
+	Fields []*slackField `json:"fields,omitempty"`
+}
+
+type slackMessage struct {
+	Text        string             `json:"text,omitempty"`
+	Attachments []*slackAttachment `json:"attachments,omitempty"`
+}
+
+// SlackNotifier sends reports to a slack incoming webhook.
+// Incoming webhooks cannot edit messages, so every update
+// is sent as a new message.
+type SlackNotifier struct {
+	url    string
+	res    i18n.Resources
+	logger *slog.Logger
+}
+
+func NewSlackNotifier(url string, logger *slog.Logger) *SlackNotifier {
+	return &SlackNotifier{
+		url:    url,
+		res:    i18n.NewResources("de-DE"),
+		logger: logger.With(logging.Component, "slack"),
+	}
+}
+
+func (s *SlackNotifier) SendReport(report *reportstorage.Report) error {
+	fields := []*slackField{}
+
+	for _, f := range reportFacts(s.res, report) {
+		fields = append(fields, &slackField{Title: f.Title, Value: f.Value, Short: true})
+	}
+
+	if err := postJSON(s.url, nil, &slackMessage{
+		Attachments: []*slackAttachment{{
+			Color:  "warning",
+			Title:  s.res.Warning(),
+			Text:   s.res.UnexpectedEvent(),
+			Fields: fields,
+		}},
+	}); err != nil {
+		return fmt.Errorf("cannot send report to slack: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackNotifier) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	text := updateText(s.res, report, kind)
+
+	if text == "" {
+		return nil
+	}
+
+	if err := postJSON(s.url, nil, &slackMessage{
+		Text: fmt.Sprintf("*%v/%v*: %v", report.Namespace, report.Resource, text),
+	}); err != nil {
+		return fmt.Errorf("cannot send update to slack: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackNotifier) ResolveReport(report *reportstorage.Report) error {
+	if err := postJSON(s.url, nil, &slackMessage{
+		Text: fmt.Sprintf("*%v/%v*: %v", report.Namespace, report.Resource, s.res.FollowupResolved(report.ReportStoppedBy)),
+	}); err != nil {
+		return fmt.Errorf("cannot send resolution to slack: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SlackNotifier) SendInternalError(err error) {
+	if err := postJSON(s.url, nil, &slackMessage{
+		Attachments: []*slackAttachment{{
+			Color: "danger",
+			Title: s.res.InternalError(),
+			Text:  err.Error(),
+		}},
+	}); err != nil {
+		s.logger.Error("cannot send internal error to slack", logging.Err(err))
+	}
+}
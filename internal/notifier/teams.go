@@ -0,0 +1,110 @@
+package notifier
+
+import (
+	"fmt"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+)
+
+const (
+	teamsColorWarning = "FFA500"
+	teamsColorError   = "FF0000"
+	teamsColorSuccess = "2EB886"
+)
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsSection struct {
+	Text  string       `json:"text,omitempty"`
+	Facts []*teamsFact `json:"facts,omitempty"`
+}
+
+// teamsCard is a legacy actionable message card,
+// which is accepted by office 365 connectors.
+type teamsCard struct {
+	Type       string          `json:"@type"`
+	Context    string          `json:"@context"`
+	ThemeColor string          `json:"themeColor"`
+	Summary    string          `json:"summary"`
+	Title      string          `json:"title"`
+	Text       string          `json:"text,omitempty"`
+	Sections   []*teamsSection `json:"sections,omitempty"`
+}
+
+func newTeamsCard(color, title, text string) *teamsCard {
+	return &teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: color,
+		Summary:    title,
+		Title:      title,
+		Text:       text,
+	}
+}
+
+// TeamsNotifier sends reports as connector cards to a
+// microsoft teams incoming webhook. Like slack, every
+// update is sent as a new card.
+type TeamsNotifier struct {
+	url    string
+	res    i18n.Resources
+	logger *slog.Logger
+}
+
+func NewTeamsNotifier(url string, logger *slog.Logger) *TeamsNotifier {
+	return &TeamsNotifier{
+		url:    url,
+		res:    i18n.NewResources("de-DE"),
+		logger: logger.With(logging.Component, "teams"),
+	}
+}
+
+func (t *TeamsNotifier) SendReport(report *reportstorage.Report) error {
+	facts := []*teamsFact{}
+
+	for _, f := range reportFacts(t.res, report) {
+		facts = append(facts, &teamsFact{Name: f.Title, Value: f.Value})
+	}
+
+	card := newTeamsCard(teamsColorWarning, t.res.Warning(), t.res.UnexpectedEvent())
+	card.Sections = []*teamsSection{{Facts: facts}}
+
+	if err := postJSON(t.url, nil, card); err != nil {
+		return fmt.Errorf("cannot send report to teams: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TeamsNotifier) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	text := updateText(t.res, report, kind)
+
+	if text == "" {
+		return nil
+	}
+
+	if err := postJSON(t.url, nil, newTeamsCard(teamsColorWarning, fmt.Sprintf("%v/%v", report.Namespace, report.Resource), text)); err != nil {
+		return fmt.Errorf("cannot send update to teams: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TeamsNotifier) ResolveReport(report *reportstorage.Report) error {
+	if err := postJSON(t.url, nil, newTeamsCard(teamsColorSuccess, fmt.Sprintf("%v/%v", report.Namespace, report.Resource), t.res.FollowupResolved(report.ReportStoppedBy))); err != nil {
+		return fmt.Errorf("cannot send resolution to teams: %w", err)
+	}
+
+	return nil
+}
+
+func (t *TeamsNotifier) SendInternalError(err error) {
+	if err := postJSON(t.url, nil, newTeamsCard(teamsColorError, t.res.InternalError(), err.Error())); err != nil {
+		t.logger.Error("cannot send internal error to teams", logging.Err(err))
+	}
+}
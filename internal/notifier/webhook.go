@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"fmt"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"time"
+)
+
+const (
+	webhookEventReportCreated  = "report_created"
+	webhookEventReportUpdated  = "report_updated"
+	webhookEventReportReopened = "report_reopened"
+	webhookEventReportResolved = "report_resolved"
+	webhookEventInternalError  = "internal_error"
+)
+
+type webhookReport struct {
+	ID             string     `json:"id"`
+	ReportedObject string     `json:"reported_object"`
+	Namespace      string     `json:"namespace"`
+	Reason         string     `json:"reason"`
+	Resource       string     `json:"resource"`
+	Message        string     `json:"message"`
+	Count          int32      `json:"count"`
+	ReportTimes    int        `json:"report_times"`
+	AssignedTo     string     `json:"assigned_to,omitempty"`
+	Resolved       bool       `json:"resolved"`
+	ResolvedBy     string     `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+type webhookPayload struct {
+	Event  string         `json:"event"`
+	Time   time.Time      `json:"time"`
+	Report *webhookReport `json:"report,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func newWebhookReport(report *reportstorage.Report) *webhookReport {
+	w := &webhookReport{
+		ID:             report.ID.String(),
+		ReportedObject: string(report.ReportedObject),
+		Namespace:      report.Namespace,
+		Reason:         report.Reason,
+		Resource:       report.Resource,
+		Message:        report.Msg,
+		Count:          report.Count,
+		ReportTimes:    report.ReportTimes,
+		AssignedTo:     report.AssignedTo,
+		Resolved:       report.ReportStopped,
+		ResolvedBy:     report.ReportStoppedBy,
+	}
+
+	// Open reports have no resolve time
+	if report.ReportStopped {
+		at := report.ReportStoppedAt
+		w.ResolvedAt = &at
+	}
+
+	return w
+}
+
+// WebhookNotifier posts every change of a report as
+// generic json document to the given url.
+type WebhookNotifier struct {
+	url     string
+	headers map[string]string
+	logger  *slog.Logger
+}
+
+func NewWebhookNotifier(url string, headers map[string]string, logger *slog.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:     url,
+		headers: headers,
+		logger:  logger.With(logging.Component, "webhook"),
+	}
+}
+
+func (w *WebhookNotifier) send(event string, report *reportstorage.Report) error {
+	if err := postJSON(w.url, w.headers, &webhookPayload{
+		Event:  event,
+		Time:   time.Now(),
+		Report: newWebhookReport(report),
+	}); err != nil {
+		return fmt.Errorf("cannot send %v to webhook: %w", event, err)
+	}
+
+	return nil
+}
+
+func (w *WebhookNotifier) SendReport(report *reportstorage.Report) error {
+	return w.send(webhookEventReportCreated, report)
+}
+
+func (w *WebhookNotifier) UpdateReport(report *reportstorage.Report, kind UpdateKind) error {
+	if kind == Reopened {
+		return w.send(webhookEventReportReopened, report)
+	}
+
+	return w.send(webhookEventReportUpdated, report)
+}
+
+func (w *WebhookNotifier) ResolveReport(report *reportstorage.Report) error {
+	return w.send(webhookEventReportResolved, report)
+}
+
+func (w *WebhookNotifier) SendInternalError(err error) {
+	if err := postJSON(w.url, w.headers, &webhookPayload{
+		Event: webhookEventInternalError,
+		Time:  time.Now(),
+		Error: err.Error(),
+	}); err != nil {
+		w.logger.Error("cannot send internal error to webhook", logging.Err(err))
+	}
+}
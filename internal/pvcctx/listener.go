@@ -5,63 +5,82 @@ import (
 	"fmt"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8sbot/internal/k8s"
-	"k8sbot/internal/mattermost"
-	"log"
+	"k8sbot/internal/listener"
+	"k8sbot/internal/metrics"
+	"k8sbot/internal/reporter"
+	"k8sbot/internal/logging"
+	"log/slog"
 	"time"
 )
 
+const interval = 10 * time.Second
+
 type EventListener struct {
-	mattermost            *mattermost.MattermostHandler
+	reporter              *reporter.Reporter
 	api                   *k8s.KubernetesApi
 	warnOnPercentageUsage int
+	health                *listener.Health
+	logger                *slog.Logger
 }
 
-func NewEventListener(handler *mattermost.MattermostHandler, api *k8s.KubernetesApi, warnOnPercentageUsage int) *EventListener {
+func NewEventListener(reporter *reporter.Reporter, api *k8s.KubernetesApi, warnOnPercentageUsage int, logger *slog.Logger) *EventListener {
 	return &EventListener{
-		mattermost: handler,
+		reporter: reporter,
 		api: api,
 		warnOnPercentageUsage: warnOnPercentageUsage,
+		health: listener.NewHealth(interval),
+		logger: logger.With(logging.Component, "pvcctx"),
 	}
 }
 
-func (e *EventListener) check() error {
-	namespaceList, err := e.api.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{})
+func (e *EventListener) check(ctx context.Context) error {
+	defer metrics.ObserveCycle(e.Name(), time.Now())
+
+	namespaceList, err := e.api.CoreV1().Namespaces().List(ctx, v1.ListOptions{})
 
 	if err != nil {
 		return fmt.Errorf("cannot get namespaces: %w", err)
 	}
 
 	for _, namespace := range namespaceList.Items {
-		pvcList, err := e.api.CoreV1().PersistentVolumeClaims(namespace.GetName()).List(context.Background(), v1.ListOptions{})
+		pvcList, err := e.api.CoreV1().PersistentVolumeClaims(namespace.GetName()).List(ctx, v1.ListOptions{})
 
 		if err != nil {
 			return fmt.Errorf("cannot get pvcs from namespace %v: %w", namespace.GetName(), err)
 		}
 
 		for _, pvc := range pvcList.Items {
-			log.Println("PVC: ", pvc.GetName())
+			e.logger.Debug("found pvc", logging.Namespace, pvc.GetNamespace(), logging.Object, pvc.GetName())
 		}
 	}
 
 	return nil
 }
 
-func (e *EventListener) Listen(done <-chan bool) error {
-	ticket := time.NewTicker(10 * time.Second)
-
-	go func() {
-		for {
-			select {
-			case <-done:
-				ticket.Stop()
-				return
-			case _ = <-ticket.C:
-				if err := e.check(); err != nil {
-					e.mattermost.SendInternalError(err)
-				}
+func (e *EventListener) Name() string {
+	return "pvcctx"
+}
+
+func (e *EventListener) Health() *listener.Health {
+	return e.health
+}
+
+// Listen checks the cluster once per interval until the context
+// is done. It returns the error of the first failed cycle.
+func (e *EventListener) Listen(ctx context.Context) error {
+	ticket := time.NewTicker(interval)
+	defer ticket.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _ = <-ticket.C:
+			if err := e.check(ctx); err != nil {
+				return err
 			}
-		}
-	}()
 
-	return nil
+			e.health.Record(nil)
+		}
+	}
 }
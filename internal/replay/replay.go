@@ -0,0 +1,171 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	"k8sbot/internal/eventctx"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/reporter"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// recording is a json value of a recorded file. It is either a
+// single event, a list of events like the output of
+// `kubectl get events -o json` or a watch event of
+// `kubectl get events -w -o json --output-watch-events`.
+type recording struct {
+	Items  []corev1.Event  `json:"items"`
+	Object json.RawMessage `json:"object"`
+}
+
+// ReadEvents decodes the recorded events of the reader. The
+// file may contain any number of json values, one per line
+// or pretty printed, as written by kubectl.
+func ReadEvents(reader io.Reader) ([]corev1.Event, error) {
+	decoder := json.NewDecoder(reader)
+	events := []corev1.Event{}
+
+	for {
+		var raw json.RawMessage
+
+		if err := decoder.Decode(&raw); errors.Is(err, io.EOF) {
+			return events, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot decode recorded events: %w", err)
+		}
+
+		rec := &recording{}
+
+		if err := json.Unmarshal(raw, rec); err != nil {
+			return nil, fmt.Errorf("cannot decode recorded events: %w", err)
+		}
+
+		if rec.Items != nil {
+			events = append(events, rec.Items...)
+			continue
+		} else if rec.Object != nil {
+			raw = rec.Object
+		}
+
+		event := corev1.Event{}
+
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, fmt.Errorf("cannot decode recorded event: %w", err)
+		}
+
+		events = append(events, event)
+	}
+}
+
+// Replayer feeds recorded events through the event listener in the
+// order they happened. The clock of the reporter follows the events
+// and the reports are checked once per reporter.CheckInterval in
+// between, so follow-ups and alert storms happen like in the cluster.
+type Replayer struct {
+	listener *eventctx.EventListener
+	reporter *reporter.Reporter
+	now      time.Time
+	logger   *slog.Logger
+}
+
+// NewReplayer creates a new replayer and sets
+// itself as clock of the reporter.
+func NewReplayer(listener *eventctx.EventListener, reporter *reporter.Reporter, logger *slog.Logger) *Replayer {
+	r := &Replayer{
+		listener: listener,
+		reporter: reporter,
+		logger:   logger.With(logging.Component, "replay"),
+	}
+
+	reporter.SetClock(r.Now)
+
+	return r
+}
+
+// Now returns the time of the replayed event. Before
+// the first event, the current time is returned.
+func (r *Replayer) Now() time.Time {
+	if r.now.IsZero() {
+		return time.Now()
+	}
+
+	return r.now
+}
+
+// Replay processes the events in the order of their timestamps.
+// Events without timestamp happen at the time of the previous event.
+// After the last event, the reports are checked once more.
+func (r *Replayer) Replay(events []corev1.Event) error {
+	sort.SliceStable(events, func(i, j int) bool {
+		return timestamp(&events[i]).Before(timestamp(&events[j]))
+	})
+
+	var nextCheck time.Time
+
+	for i := range events {
+		event := &events[i]
+
+		if at := timestamp(event); !at.IsZero() {
+			if nextCheck.IsZero() {
+				nextCheck = at.Add(reporter.CheckInterval)
+			}
+
+			for !nextCheck.After(at) {
+				if err := r.check(nextCheck); err != nil {
+					return err
+				}
+
+				nextCheck = nextCheck.Add(reporter.CheckInterval)
+			}
+
+			r.now = at
+		}
+
+		if err := r.listener.Process(event); err != nil {
+			return fmt.Errorf("cannot process event %v: %w", event.Name, err)
+		}
+	}
+
+	if nextCheck.IsZero() {
+		nextCheck = r.Now()
+	}
+
+	if err := r.check(nextCheck); err != nil {
+		return err
+	}
+
+	r.logger.Info("replayed events", "events", len(events))
+
+	return nil
+}
+
+func (r *Replayer) check(at time.Time) error {
+	r.now = at
+
+	if err := r.reporter.CheckReports(); err != nil {
+		return fmt.Errorf("cannot check reports at %v: %w", at.Format(time.RFC3339), err)
+	}
+
+	return nil
+}
+
+// timestamp returns the time of the last occurrence of the event.
+// Events without last timestamp fall back to the event time, the
+// first timestamp and the creation time.
+func timestamp(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
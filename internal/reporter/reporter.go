@@ -0,0 +1,531 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golangee/uuid"
+	"k8s.io/apimachinery/pkg/types"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/logging"
+	"k8sbot/internal/metrics"
+	"k8sbot/internal/notifier"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// CheckInterval is the interval, in which the reports
+// are checked for follow-ups and the end of a storm.
+const CheckInterval = 5 * time.Second
+
+// Reporter manages the lifecycle of reports. It decides when a
+// report is created, followed up, reopened or resolved, keeps
+// the storage up to date and tells the notifier about it.
+type Reporter struct {
+	storage          reportstorage.ReportStorage
+	notifier         notifier.Notifier
+	followUpInterval time.Duration
+	storm            *stormDetector
+	clusterName      string
+	res              i18n.Resources
+	logger           *slog.Logger
+	// now returns the current time, see SetClock.
+	now func() time.Time
+}
+
+// NewReporter creates a new reporter. When more than stormThreshold
+// reports are created within a minute, the following reports are
+// summarized in one report. A threshold of 0 disables the detection.
+// The cluster name is matched against the cluster of silences.
+func NewReporter(storage reportstorage.ReportStorage, notifier notifier.Notifier, followUpInterval time.Duration, stormThreshold int, clusterName string, logger *slog.Logger) *Reporter {
+	return &Reporter{
+		storage:          storage,
+		notifier:         notifier,
+		followUpInterval: followUpInterval,
+		storm:            newStormDetector(stormThreshold),
+		clusterName:      clusterName,
+		res:              i18n.NewResources("de-DE"),
+		logger:           logger.With(logging.Component, "reporter"),
+		now:              time.Now,
+	}
+}
+
+// SetClock replaces the clock of the reporter, so recorded
+// events can be replayed in the time they happened.
+func (r *Reporter) SetClock(now func() time.Time) {
+	r.now = now
+}
+
+func (r *Reporter) Listen(ctx context.Context) error {
+	ticker := time.NewTicker(CheckInterval)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case _ = <-ticker.C:
+				if err := r.CheckReports(); err != nil {
+					r.SendInternalError(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// CheckReports ends a finished alert storm and sends the due
+// follow-ups. Listen calls it once per CheckInterval.
+func (r *Reporter) CheckReports() error {
+	if st := r.storm.end(r.now()); st != nil {
+		if err := r.endStorm(st); err != nil {
+			return err
+		}
+	}
+
+	reports, err := r.storage.ReadAll()
+
+	if err != nil {
+		return fmt.Errorf("cannot read reports: %w", err)
+	}
+
+	for _, report := range reports {
+		if !report.ReportStopped && !report.IsSnoozed(r.now()) {
+			if err := r.followUp(report); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// followUp notifies about an open report, when its event
+// occurred again since the last notification. To avoid
+// flooding the sinks, only one follow-up per interval is sent.
+func (r *Reporter) followUp(report *reportstorage.Report) error {
+	now := r.now()
+
+	if report.Count <= report.NotifiedCount || now.Before(report.LastReportUpdate.Add(r.followUpInterval)) {
+		return nil
+	}
+
+	if err := r.storage.IncreaseCounter(report.ID); err != nil {
+		return fmt.Errorf("cannot increase counter of report: %w", err)
+	}
+
+	report, err := r.read(report.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.notifier.UpdateReport(report, notifier.Recurred); err != nil {
+		return fmt.Errorf("cannot send follow-up of report: %w", err)
+	}
+
+	if err := r.storage.SetNotified(report.ID, report.Count, now); err != nil {
+		return fmt.Errorf("cannot set notification of report: %w", err)
+	}
+
+	return nil
+}
+
+// SendReport creates a new report for the given object, when
+// nothing was reported for it yet and the reason isn't muted.
+// For already reported objects, the count will be updated.
+func (r *Reporter) SendReport(objectID types.UID, namespace, reason, resource, message string, count int32) error {
+	mute, err := reportstorage.FindMute(r.storage, r.now(), namespace, reason)
+
+	if err != nil {
+		return fmt.Errorf("cannot read mutes: %w", err)
+	}
+
+	if mute != nil {
+		return nil
+	}
+
+	existing, err := r.storage.ReadByObjectID(objectID)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		silence, err := reportstorage.FindSilence(r.storage, r.now(), r.clusterName, namespace, reason, resource)
+
+		if err != nil {
+			return fmt.Errorf("cannot read silences: %w", err)
+		}
+
+		if silence != nil {
+			r.logger.Debug("silenced report", logging.Namespace, namespace, logging.Reason, reason, logging.Object, resource, logging.SilenceID, silence.ID.String())
+			return nil
+		}
+
+		if st, summarized := r.storm.register(r.now(), objectID, namespace, reason); st != nil {
+			return r.reportStorm(st)
+		} else if summarized {
+			return nil
+		}
+
+		new := &reportstorage.Report{
+			ID:               uuid.New(),
+			ReportedObject:   objectID,
+			Namespace:        namespace,
+			Reason:           reason,
+			Resource:         resource,
+			Msg:              message,
+			Count:            count,
+			NotifiedCount:    count,
+			ReportTimes:      1,
+			IsInProgress:     false,
+			LastReportUpdate: r.now(),
+			CreatedAt:        r.now(),
+			ReportStopped:    false,
+			ReportStoppedBy:  "",
+		}
+
+		if err := r.storage.Write(new); err != nil {
+			return fmt.Errorf("cannot write report: %w", err)
+		}
+
+		r.logger.Info("created report", append(new.LogAttrs(), logging.Object, resource, "count", count)...)
+		metrics.ReportsOpened.WithLabelValues(namespace, reason).Inc()
+
+		if err := r.notifier.SendReport(new); err != nil {
+			return fmt.Errorf("cannot send report: %w", err)
+		}
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read object: %w", err)
+	}
+
+	return r.updateOccurrence(existing, count, message)
+}
+
+// reportStorm creates or updates the report, which summarizes
+// the running alert storm. The summary is updated at most once
+// per minute, to keep the notifications low during the storm.
+func (r *Reporter) reportStorm(st *storm) error {
+	mute, err := reportstorage.FindMute(r.storage, r.now(), "", StormReason)
+
+	if err != nil {
+		return fmt.Errorf("cannot read mutes: %w", err)
+	}
+
+	if mute != nil {
+		return nil
+	}
+
+	now := r.now()
+	existing, err := r.storage.ReadByObjectID(st.objectID)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		report := &reportstorage.Report{
+			ID:               uuid.New(),
+			ReportedObject:   st.objectID,
+			Reason:           StormReason,
+			Resource:         r.res.AlertStorm(),
+			Msg:              r.stormSummary(st),
+			Count:            int32(st.total),
+			NotifiedCount:    int32(st.total),
+			ReportTimes:      1,
+			LastReportUpdate: now,
+			CreatedAt:        now,
+		}
+
+		if err := r.storage.Write(report); err != nil {
+			return fmt.Errorf("cannot write storm report: %w", err)
+		}
+
+		r.logger.Warn("alert storm started", append(report.LogAttrs(), "count", st.total)...)
+
+		metrics.ReportsOpened.WithLabelValues(report.Namespace, report.Reason).Inc()
+
+		if err := r.notifier.SendReport(report); err != nil {
+			return fmt.Errorf("cannot send storm report: %w", err)
+		}
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read storm report: %w", err)
+	}
+
+	if err := r.storage.SetCount(existing.ID, int32(st.total), r.stormSummary(st)); err != nil {
+		return fmt.Errorf("cannot update storm report: %w", err)
+	}
+
+	if now.Before(existing.LastReportUpdate.Add(stormUpdateInterval)) {
+		return nil
+	}
+
+	if err := r.storage.SetNotified(existing.ID, int32(st.total), now); err != nil {
+		return fmt.Errorf("cannot set notification of storm report: %w", err)
+	}
+
+	return r.update(existing.ID, notifier.StateChanged)
+}
+
+// endStorm stores the final summary of the alert storm
+// and submits its report.
+func (r *Reporter) endStorm(st *storm) error {
+	report, err := r.storage.ReadByObjectID(st.objectID)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read storm report: %w", err)
+	}
+
+	if err := r.storage.SetCount(report.ID, int32(st.total), r.stormSummary(st)); err != nil {
+		return fmt.Errorf("cannot update storm report: %w", err)
+	}
+
+	if err := r.storage.SetNotified(report.ID, int32(st.total), r.now()); err != nil {
+		return fmt.Errorf("cannot set notification of storm report: %w", err)
+	}
+
+	return r.submit(report.ID, stormResolver, false)
+}
+
+func (r *Reporter) stormSummary(st *storm) string {
+	builder := &strings.Builder{}
+	builder.WriteString(r.res.StormSummary(st.total))
+
+	for _, c := range st.counts {
+		builder.WriteString(fmt.Sprintf("\n%v / %v: %v", c.Namespace, c.Reason, c.Count))
+	}
+
+	return builder.String()
+}
+
+// SendAlert reports an object, which has no event count like an
+// alert of the alertmanager. Every time the alert fires again
+// after its report was submitted, the count is increased, so
+// the report gets reopened.
+func (r *Reporter) SendAlert(objectID types.UID, namespace, reason, resource, message string) error {
+	count := int32(1)
+
+	existing, err := r.storage.ReadByObjectID(objectID)
+
+	if err == nil {
+		count = existing.Count
+
+		if existing.ReportStopped {
+			count++
+		}
+	} else if !errors.Is(err, &reportstorage.NoReportErr{}) {
+		return fmt.Errorf("cannot read object: %w", err)
+	}
+
+	return r.SendReport(objectID, namespace, reason, resource, message, count)
+}
+
+// ResolveObject submits the open report of the given object
+// in the name of the given user. When there is no open report
+// for the object, nothing happens.
+func (r *Reporter) ResolveObject(objectID types.UID, username string) error {
+	report, err := r.storage.ReadByObjectID(objectID)
+
+	if errors.Is(err, &reportstorage.NoReportErr{}) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read object: %w", err)
+	}
+
+	if report.ReportStopped {
+		return nil
+	}
+
+	return r.submit(report.ID, username, false)
+}
+
+// updateOccurrence stores the new count of an already reported
+// event. When the report was submitted before, it will be
+// reopened immediately. Every other follow-up is sent
+// rate-limited by followUp.
+func (r *Reporter) updateOccurrence(report *reportstorage.Report, count int32, message string) error {
+	if count <= report.Count {
+		return nil
+	}
+
+	if err := r.storage.SetCount(report.ID, count, message); err != nil {
+		return fmt.Errorf("cannot update count of report: %w", err)
+	}
+
+	if !report.ReportStopped {
+		return nil
+	}
+
+	if err := r.storage.ReopenReport(report.ID); err != nil {
+		return fmt.Errorf("cannot reopen report: %w", err)
+	}
+
+	if err := r.storage.SetNotified(report.ID, count, r.now()); err != nil {
+		return fmt.Errorf("cannot set notification of report: %w", err)
+	}
+
+	metrics.ReportsOpened.WithLabelValues(report.Namespace, report.Reason).Inc()
+
+	return r.update(report.ID, notifier.Reopened)
+}
+
+// SubmitReport marks the report as submitted by the given user.
+func (r *Reporter) SubmitReport(reportID uuid.UUID, username string) error {
+	return r.submit(reportID, username, true)
+}
+
+// submit marks the report as submitted. Reports, which were
+// not acknowledged by a user, are counted as resolved.
+func (r *Reporter) submit(reportID uuid.UUID, username string, acknowledged bool) error {
+	if err := r.storage.SubmitReport(reportID, username); err != nil {
+		return fmt.Errorf("cannot update report in storage to submit: %w", err)
+	}
+
+	report, err := r.read(reportID)
+
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("submitted report", append(report.LogAttrs(), logging.User, username)...)
+
+	if acknowledged {
+		metrics.ReportsAcknowledged.WithLabelValues(report.Namespace, report.Reason).Inc()
+		metrics.TimeToAcknowledge.WithLabelValues(report.Namespace).Observe(report.ReportStoppedAt.Sub(report.CreatedAt).Seconds())
+	} else {
+		metrics.ReportsResolved.WithLabelValues(report.Namespace, report.Reason).Inc()
+	}
+
+	if err := r.notifier.ResolveReport(report); err != nil {
+		return fmt.Errorf("cannot send resolution of report: %w", err)
+	}
+
+	return nil
+}
+
+// TakeReport marks the report as in progress by the given user.
+func (r *Reporter) TakeReport(reportID uuid.UUID, username string) error {
+	if err := r.storage.SetInProgress(reportID, true); err != nil {
+		return fmt.Errorf("cannot set report in progress: %w", err)
+	}
+
+	if err := r.storage.SetAssignee(reportID, username); err != nil {
+		return fmt.Errorf("cannot set assignee of report: %w", err)
+	}
+
+	return r.update(reportID, notifier.StateChanged)
+}
+
+// SnoozeReport suppresses follow-ups of the report until the given time.
+func (r *Reporter) SnoozeReport(reportID uuid.UUID, until time.Time) error {
+	if err := r.storage.SetSnoozedUntil(reportID, until); err != nil {
+		return fmt.Errorf("cannot snooze report: %w", err)
+	}
+
+	return r.update(reportID, notifier.StateChanged)
+}
+
+// MuteReason suppresses new reports for the reason in the given
+// namespace until the given time. An empty namespace mutes the
+// reason in all namespaces. Open reports of the reason are updated.
+func (r *Reporter) MuteReason(namespace, reason string, until time.Time, username string) error {
+	mute := &reportstorage.Mute{
+		Reason:    reason,
+		Namespace: namespace,
+		Until:     until,
+		MutedBy:   username,
+	}
+
+	if err := r.storage.WriteMute(mute); err != nil {
+		return fmt.Errorf("cannot write mute: %w", err)
+	}
+
+	reports, err := r.storage.ReadAll()
+
+	if err != nil {
+		return fmt.Errorf("cannot read reports: %w", err)
+	}
+
+	for _, report := range reports {
+		if !report.ReportStopped && mute.Matches(report.Namespace, report.Reason) {
+			if err := r.update(report.ID, notifier.StateChanged); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// AddSilence validates the silence and stores it. From its start
+// until its end, no new reports are created for matching objects.
+// Already existing reports are not affected.
+func (r *Reporter) AddSilence(silence *reportstorage.Silence) error {
+	if err := silence.Validate(); err != nil {
+		return err
+	}
+
+	if silence.ID == (uuid.UUID{}) {
+		silence.ID = uuid.New()
+	}
+
+	if err := r.storage.WriteSilence(silence); err != nil {
+		return fmt.Errorf("cannot write silence: %w", err)
+	}
+
+	r.logger.Info("added silence", logging.SilenceID, silence.ID.String(), logging.Namespace, silence.Namespace, logging.Reason, silence.Reason, logging.User, silence.CreatedBy)
+
+	return nil
+}
+
+// DeleteSilence ends the silence immediately.
+func (r *Reporter) DeleteSilence(silenceID uuid.UUID) error {
+	if err := r.storage.DeleteSilence(silenceID); err != nil {
+		return fmt.Errorf("cannot delete silence: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReport removes the report from the storage, e.g. when
+// its message was deleted. New events of the object will
+// create a new report.
+func (r *Reporter) DeleteReport(reportID uuid.UUID) error {
+	if err := r.storage.Delete(reportID); err != nil {
+		return fmt.Errorf("cannot delete report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Reporter) SendInternalError(err error) {
+	r.logger.Error("internal error", logging.Err(err))
+	r.notifier.SendInternalError(err)
+}
+
+func (r *Reporter) update(reportID uuid.UUID, kind notifier.UpdateKind) error {
+	report, err := r.read(reportID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := r.notifier.UpdateReport(report, kind); err != nil {
+		return fmt.Errorf("cannot send update of report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Reporter) read(reportID uuid.UUID) (*reportstorage.Report, error) {
+	report, err := r.storage.ReadByReportID(reportID)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read report with given id: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"io"
+	"k8s.io/apimachinery/pkg/types"
+	"k8sbot/internal/i18n"
+	"k8sbot/internal/notifier"
+	"k8sbot/internal/reportstorage"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// recordingNotifier records the notifications and
+// the follow-up texts of recurring reports.
+type recordingNotifier struct {
+	res      i18n.Resources
+	sent     []*reportstorage.Report
+	updates  []notifier.UpdateKind
+	texts    []string
+	resolved []*reportstorage.Report
+}
+
+func (r *recordingNotifier) SendReport(report *reportstorage.Report) error {
+	r.sent = append(r.sent, report)
+	return nil
+}
+
+func (r *recordingNotifier) UpdateReport(report *reportstorage.Report, kind notifier.UpdateKind) error {
+	r.updates = append(r.updates, kind)
+
+	if kind == notifier.Recurred {
+		r.texts = append(r.texts, notifier.RecurredText(r.res, report))
+	}
+
+	return nil
+}
+
+func (r *recordingNotifier) ResolveReport(report *reportstorage.Report) error {
+	r.resolved = append(r.resolved, report)
+	return nil
+}
+
+func (r *recordingNotifier) SendInternalError(err error) {
+}
+
+// testClock is a clock, which only moves when it is told to.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestReporter(stormThreshold int) (*Reporter, *recordingNotifier, *testClock) {
+	n := &recordingNotifier{res: i18n.NewResources("de-DE")}
+	clock := &testClock{now: time.Now()}
+	r := NewReporter(reportstorage.NewInMemoryReportStorage(), n, time.Minute, stormThreshold, "", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.SetClock(clock.Now)
+
+	return r, n, clock
+}
+
+func TestReporterFollowUps(t *testing.T) {
+	res := i18n.NewResources("de-DE")
+
+	tests := []struct {
+		name    string
+		count   int32
+		after   time.Duration
+		submit  bool
+		snooze  bool
+		updates []notifier.UpdateKind
+		texts   []string
+	}{
+		{
+			name:  "no new occurrence",
+			count: 4,
+			after: time.Minute,
+		},
+		{
+			name:  "within the interval",
+			count: 5,
+			after: 30 * time.Second,
+		},
+		{
+			name:    "occurred again",
+			count:   5,
+			after:   time.Minute,
+			updates: []notifier.UpdateKind{notifier.Recurred},
+			texts:   []string{res.FollowupOccurredAgain(5, 1)},
+		},
+		{
+			name:    "count jump",
+			count:   8,
+			after:   time.Minute,
+			updates: []notifier.UpdateKind{notifier.Recurred},
+			texts:   []string{res.FollowupCountJump(4, 8)},
+		},
+		{
+			name:   "snoozed",
+			count:  5,
+			after:  time.Minute,
+			snooze: true,
+		},
+		{
+			name:    "reopened immediately",
+			count:   5,
+			after:   time.Second,
+			submit:  true,
+			updates: []notifier.UpdateKind{notifier.Reopened},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, n, clock := newTestReporter(0)
+
+			if err := r.SendReport("uid", "default", "BackOff", "api", "failed", 4); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := r.storage.ReadByObjectID("uid")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.submit {
+				if err := r.SubmitReport(report.ID, "alice"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.snooze {
+				if err := r.SnoozeReport(report.ID, clock.now.Add(time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+
+				n.updates = nil
+			}
+
+			clock.now = clock.now.Add(tt.after)
+
+			if err := r.SendReport("uid", "default", "BackOff", "api", "failed", tt.count); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.CheckReports(); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(n.updates) != len(tt.updates) {
+				t.Fatalf("expected updates %v, got %v", tt.updates, n.updates)
+			}
+
+			for i := range tt.updates {
+				if n.updates[i] != tt.updates[i] {
+					t.Errorf("expected updates %v, got %v", tt.updates, n.updates)
+				}
+			}
+
+			if len(n.texts) != len(tt.texts) || (len(tt.texts) > 0 && n.texts[0] != tt.texts[0]) {
+				t.Errorf("expected texts %q, got %q", tt.texts, n.texts)
+			}
+		})
+	}
+}
+
+func TestReporterSendsOneFollowUpPerInterval(t *testing.T) {
+	r, n, clock := newTestReporter(0)
+	objectID := types.UID("uid")
+
+	if err := r.SendReport(objectID, "default", "BackOff", "api", "failed", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for count := int32(2); count <= 7; count++ {
+		clock.now = clock.now.Add(20 * time.Second)
+
+		if err := r.SendReport(objectID, "default", "BackOff", "api", "failed", count); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := r.CheckReports(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Of the six occurrences every 20 seconds, only the ones
+	// after 60 and 120 seconds are followed up
+	if len(n.updates) != 2 {
+		t.Fatalf("expected two follow-ups, got %v", n.updates)
+	}
+}
@@ -0,0 +1,160 @@
+package reporter
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// StormReason is the reason of the report, that
+	// summarizes an alert storm.
+	StormReason = "AlertStorm"
+	// stormResolver is shown as user, who submitted
+	// the report of an alert storm after it ended.
+	stormResolver = "k8sbot"
+	stormWindow   = time.Minute
+	// stormUpdateInterval is the minimum time between two
+	// updates of the summary of a running alert storm.
+	stormUpdateInterval = time.Minute
+)
+
+// stormCount is the number of suppressed reports
+// of a reason in a namespace.
+type stormCount struct {
+	Namespace string
+	Reason    string
+	Count     int
+}
+
+// storm is a snapshot of a running alert storm.
+type storm struct {
+	objectID types.UID
+	total    int
+	counts   []*stormCount
+}
+
+// stormDetector counts the new reports of the last minute. When
+// more than threshold reports were created, an alert storm starts
+// and every further report is only counted for the summary. The
+// storm ends, when the rate drops below the threshold again.
+// Summarized objects are remembered while the storm lasts, so they
+// don't count twice. After the storm, they are reported as usual.
+type stormDetector struct {
+	threshold int
+
+	mutex      sync.Mutex
+	created    []time.Time
+	active     bool
+	startedAt  time.Time
+	counts     map[string]*stormCount
+	summarized map[types.UID]bool
+}
+
+func newStormDetector(threshold int) *stormDetector {
+	return &stormDetector{
+		threshold:  threshold,
+		created:    []time.Time{},
+		summarized: map[types.UID]bool{},
+	}
+}
+
+// register counts a new report and returns true, when the report
+// should not be sent on its own. The running storm is returned,
+// when its summary should be updated.
+func (s *stormDetector) register(now time.Time, objectID types.UID, namespace, reason string) (*storm, bool) {
+	if s.threshold <= 0 {
+		return nil, false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune(now)
+
+	if s.summarized[objectID] {
+		return nil, true
+	}
+
+	s.created = append(s.created, now)
+
+	if !s.active {
+		if len(s.created) <= s.threshold {
+			return nil, false
+		}
+
+		s.active = true
+		s.startedAt = now
+		s.counts = map[string]*stormCount{}
+	}
+
+	key := namespace + "/" + reason
+
+	if _, ok := s.counts[key]; !ok {
+		s.counts[key] = &stormCount{Namespace: namespace, Reason: reason}
+	}
+
+	s.counts[key].Count++
+	s.summarized[objectID] = true
+
+	return s.snapshot(), true
+}
+
+// end returns the final state of the storm, when the rate
+// dropped below the threshold. Otherwise nil is returned.
+func (s *stormDetector) end(now time.Time) *storm {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.active {
+		return nil
+	}
+
+	s.prune(now)
+
+	if len(s.created) >= s.threshold {
+		return nil
+	}
+
+	s.active = false
+	s.summarized = map[types.UID]bool{}
+
+	return s.snapshot()
+}
+
+func (s *stormDetector) prune(now time.Time) {
+	tmp := []time.Time{}
+
+	for _, t := range s.created {
+		if now.Sub(t) < stormWindow {
+			tmp = append(tmp, t)
+		}
+	}
+
+	s.created = tmp
+}
+
+func (s *stormDetector) snapshot() *storm {
+	st := &storm{
+		objectID: types.UID(fmt.Sprintf("storm-%v", s.startedAt.Unix())),
+		counts:   []*stormCount{},
+	}
+
+	for _, c := range s.counts {
+		count := *c
+		st.counts = append(st.counts, &count)
+		st.total += c.Count
+	}
+
+	sort.Slice(st.counts, func(i, j int) bool {
+		if st.counts[i].Count != st.counts[j].Count {
+			return st.counts[i].Count > st.counts[j].Count
+		}
+
+		return st.counts[i].Namespace+st.counts[i].Reason < st.counts[j].Namespace+st.counts[j].Reason
+	})
+
+	return st
+}
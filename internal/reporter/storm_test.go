@@ -0,0 +1,134 @@
+package reporter
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"testing"
+	"time"
+)
+
+func TestStormDetectorRegister(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int
+		objects    []types.UID
+		interval   time.Duration
+		summarized []bool
+		total      int
+	}{
+		{
+			name:       "disabled",
+			threshold:  0,
+			objects:    []types.UID{"a", "b", "c"},
+			summarized: []bool{false, false, false},
+		},
+		{
+			name:       "below the threshold",
+			threshold:  3,
+			objects:    []types.UID{"a", "b", "c"},
+			summarized: []bool{false, false, false},
+		},
+		{
+			name:       "above the threshold",
+			threshold:  2,
+			objects:    []types.UID{"a", "b", "c", "d"},
+			summarized: []bool{false, false, true, true},
+			total:      2,
+		},
+		{
+			name:       "summarized objects count once",
+			threshold:  2,
+			objects:    []types.UID{"a", "b", "c", "c", "c"},
+			summarized: []bool{false, false, true, true, true},
+			total:      1,
+		},
+		{
+			name:       "spread over more than a minute",
+			threshold:  2,
+			objects:    []types.UID{"a", "b", "c", "d"},
+			interval:   40 * time.Second,
+			summarized: []bool{false, false, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStormDetector(tt.threshold)
+			now := time.Now()
+			var last *storm
+
+			for i, objectID := range tt.objects {
+				st, summarized := s.register(now.Add(time.Duration(i)*tt.interval), objectID, "default", "BackOff")
+
+				if summarized != tt.summarized[i] {
+					t.Fatalf("expected object %v to be summarized %v, got %v", i, tt.summarized[i], summarized)
+				}
+
+				if st != nil {
+					last = st
+				}
+			}
+
+			if tt.total == 0 {
+				if last != nil {
+					t.Fatalf("expected no storm, got %v reports", last.total)
+				}
+
+				return
+			}
+
+			if last == nil || last.total != tt.total {
+				t.Fatalf("expected a storm of %v reports, got %+v", tt.total, last)
+			}
+		})
+	}
+}
+
+func TestStormDetectorEnd(t *testing.T) {
+	s := newStormDetector(2)
+	now := time.Now()
+
+	for i, objectID := range []types.UID{"a", "b", "c", "d"} {
+		s.register(now.Add(time.Duration(i)*time.Second), objectID, "default", "BackOff")
+	}
+
+	if st := s.end(now.Add(10 * time.Second)); st != nil {
+		t.Fatal("expected the storm to last while the rate is high")
+	}
+
+	st := s.end(now.Add(stormWindow + 10*time.Second))
+
+	if st == nil {
+		t.Fatal("expected the storm to end, when the rate dropped")
+	}
+
+	if st.total != 2 || len(st.counts) != 1 || st.counts[0].Namespace != "default" || st.counts[0].Reason != "BackOff" {
+		t.Fatalf("expected a summary of two reports, got %+v", st)
+	}
+
+	if s.end(now.Add(stormWindow+20*time.Second)) != nil {
+		t.Fatal("expected the storm to end only once")
+	}
+
+	// Summarized objects are reported on their own after the storm
+	if _, summarized := s.register(now.Add(stormWindow+30*time.Second), "c", "default", "BackOff"); summarized {
+		t.Fatal("expected the object to be reported after the storm")
+	}
+}
+
+func TestStormDetectorCountsByNamespaceAndReason(t *testing.T) {
+	s := newStormDetector(1)
+	now := time.Now()
+	var st *storm
+
+	for i, event := range [][2]string{{"default", "BackOff"}, {"default", "BackOff"}, {"kube-system", "FailedScheduling"}, {"default", "BackOff"}} {
+		st, _ = s.register(now, types.UID(rune('a'+i)), event[0], event[1])
+	}
+
+	if st == nil || st.total != 3 || len(st.counts) != 2 {
+		t.Fatalf("expected a storm of three reports in two groups, got %+v", st)
+	}
+
+	if first := st.counts[0]; first.Namespace != "default" || first.Count != 2 {
+		t.Fatalf("expected the largest group first, got %+v", first)
+	}
+}
@@ -0,0 +1,408 @@
+package reportstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golangee/uuid"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+	"k8sbot/internal/logging"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// configMapKey is the key of the ConfigMap, which holds the state.
+	configMapKey = "state.json"
+	// maxStateSize keeps the state below the limit of 1 MiB of
+	// a ConfigMap, with some room for its metadata.
+	maxStateSize = 900 * 1024
+	// cacheTTL is the time, a read ConfigMap is used for further
+	// reads, so a cycle of the listeners only reads it once.
+	cacheTTL = 5 * time.Second
+)
+
+// storageState is the content of the ConfigMap.
+type storageState struct {
+	Reports  []*Report  `json:"reports"`
+	Mutes    []*Mute    `json:"mutes"`
+	Silences []*Silence `json:"silences"`
+}
+
+// ConfigMapReportStorage keeps the reports, mutes and silences as
+// json in a ConfigMap, so they survive restarts and are shared by all
+// replicas of the bot. Reads use the ConfigMap of the last read or
+// write for cacheTTL, so changes of other replicas show up with
+// that delay. Changes are written with the resource version of the
+// cached ConfigMap, so concurrent changes of other replicas are
+// retried with a fresh read instead of overwritten. Submitted
+// reports are dropped after the retention or, when the state gets
+// too large, the oldest first. The ConfigMap is created, when it
+// doesn't exist.
+type ConfigMapReportStorage struct {
+	client    v1.ConfigMapInterface
+	name      string
+	retention time.Duration
+	logger    *slog.Logger
+
+	mutex    sync.Mutex
+	cached   *corev1.ConfigMap
+	cachedAt time.Time
+	// now returns the current time, see SetClock.
+	now func() time.Time
+}
+
+func NewConfigMapReportStorage(client v1.ConfigMapInterface, name string, retention time.Duration, logger *slog.Logger) *ConfigMapReportStorage {
+	return &ConfigMapReportStorage{
+		client:    client,
+		name:      name,
+		retention: retention,
+		logger:    logger.With(logging.Component, "storage"),
+		now:       time.Now,
+	}
+}
+
+// SetClock replaces the clock, which the cache, the retention
+// and the expiry of mutes and silences are checked by.
+func (c *ConfigMapReportStorage) SetClock(now func() time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = now
+}
+
+// get returns a copy of the ConfigMap. Unless fresh is set,
+// the cached ConfigMap is used, when it isn't outdated.
+func (c *ConfigMapReportStorage) get(fresh bool) (*corev1.ConfigMap, error) {
+	c.mutex.Lock()
+
+	if !fresh && c.cached != nil && c.now().Sub(c.cachedAt) < cacheTTL {
+		defer c.mutex.Unlock()
+		return c.cached.DeepCopy(), nil
+	}
+
+	c.mutex.Unlock()
+
+	configMap, err := c.client.Get(context.Background(), c.name, metav1.GetOptions{})
+
+	if apierrors.IsNotFound(err) {
+		configMap, err = c.client.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: c.name},
+			Data:       map[string]string{},
+		}, metav1.CreateOptions{})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read configmap %v: %w", c.name, err)
+	}
+
+	c.remember(configMap)
+
+	return configMap, nil
+}
+
+// remember caches a copy of the read or written ConfigMap.
+func (c *ConfigMapReportStorage) remember(configMap *corev1.ConfigMap) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.cached = configMap.DeepCopy()
+	c.cachedAt = c.now()
+}
+
+// load reads the ConfigMap and decodes its state
+// into a storage, which the changes are applied to.
+func (c *ConfigMapReportStorage) load(fresh bool) (*corev1.ConfigMap, *InMemoryReportStorage, error) {
+	configMap, err := c.get(fresh)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	storage := NewInMemoryReportStorage()
+	storage.SetClock(c.clock())
+	data, ok := configMap.Data[configMapKey]
+
+	if !ok {
+		return configMap, storage, nil
+	}
+
+	state := &storageState{}
+
+	if err := json.Unmarshal([]byte(data), state); err != nil {
+		return nil, nil, fmt.Errorf("cannot decode configmap %v: %w", c.name, err)
+	}
+
+	for _, s := range state.Silences {
+		if err := s.Validate(); err != nil {
+			c.logger.Warn("ignored invalid silence", logging.SilenceID, s.ID.String(), logging.Err(err))
+			continue
+		}
+
+		storage.silences = append(storage.silences, s)
+	}
+
+	if state.Reports != nil {
+		storage.reports = state.Reports
+	}
+
+	if state.Mutes != nil {
+		storage.mutes = state.Mutes
+	}
+
+	return configMap, storage, nil
+}
+
+// update applies the change to the current state and writes it back.
+// The first try uses the cached ConfigMap, a retry after a conflict
+// reads it again. Expired mutes and silences and the submitted
+// reports after the retention are dropped on every write.
+func (c *ConfigMapReportStorage) update(change func(storage *InMemoryReportStorage) error) error {
+	fresh := false
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, storage, err := c.load(fresh)
+		fresh = true
+
+		if err != nil {
+			return err
+		}
+
+		if err := change(storage); err != nil {
+			return err
+		}
+
+		data, err := c.encode(storage)
+
+		if err != nil {
+			return err
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+
+		configMap.Data[configMapKey] = string(data)
+
+		updated, err := c.client.Update(context.Background(), configMap, metav1.UpdateOptions{})
+
+		if err != nil {
+			return err
+		}
+
+		c.remember(updated)
+
+		return nil
+	})
+}
+
+// encode drops the submitted reports after the retention and
+// encodes the state. When the state is still too large, the
+// oldest submitted reports are dropped, until it fits.
+func (c *ConfigMapReportStorage) encode(storage *InMemoryReportStorage) ([]byte, error) {
+	now := c.clock()()
+	reports := []*Report{}
+
+	for _, r := range storage.reports {
+		if !r.ReportStopped || now.Sub(r.ReportStoppedAt) < c.retention {
+			reports = append(reports, r)
+		}
+	}
+
+	mutes, _ := storage.ReadMutes()
+	silences, _ := storage.ReadSilences()
+
+	for {
+		data, err := json.Marshal(&storageState{
+			Reports:  reports,
+			Mutes:    mutes,
+			Silences: silences,
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode state: %w", err)
+		}
+
+		if len(data) <= maxStateSize {
+			return data, nil
+		}
+
+		oldest := -1
+
+		for i, r := range reports {
+			if r.ReportStopped && (oldest < 0 || r.ReportStoppedAt.Before(reports[oldest].ReportStoppedAt)) {
+				oldest = i
+			}
+		}
+
+		if oldest < 0 {
+			return nil, fmt.Errorf("cannot store state of %v bytes in configmap %v, the limit is %v bytes", len(data), c.name, maxStateSize)
+		}
+
+		c.logger.Warn("dropped submitted report, because the state is too large", reports[oldest].LogAttrs()...)
+		reports = append(reports[:oldest], reports[oldest+1:]...)
+	}
+}
+
+func (c *ConfigMapReportStorage) clock() func() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *ConfigMapReportStorage) read() (*InMemoryReportStorage, error) {
+	_, storage, err := c.load(false)
+
+	return storage, err
+}
+
+func (c *ConfigMapReportStorage) ReadAll() ([]*Report, error) {
+	storage, err := c.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ReadAll()
+}
+
+func (c *ConfigMapReportStorage) Write(report *Report) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.Write(report)
+	})
+}
+
+func (c *ConfigMapReportStorage) ReadByReportID(reportID uuid.UUID) (*Report, error) {
+	storage, err := c.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ReadByReportID(reportID)
+}
+
+func (c *ConfigMapReportStorage) ReadByObjectID(objectID types.UID) (*Report, error) {
+	storage, err := c.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ReadByObjectID(objectID)
+}
+
+func (c *ConfigMapReportStorage) ReadByPostID(postID string) (*Report, error) {
+	storage, err := c.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ReadByPostID(postID)
+}
+
+func (c *ConfigMapReportStorage) Delete(reportID uuid.UUID) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.Delete(reportID)
+	})
+}
+
+func (c *ConfigMapReportStorage) IncreaseCounter(reportID uuid.UUID) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.IncreaseCounter(reportID)
+	})
+}
+
+func (c *ConfigMapReportStorage) SetInProgress(reportID uuid.UUID, val bool) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.SetInProgress(reportID, val)
+	})
+}
+
+func (c *ConfigMapReportStorage) SetAssignee(reportID uuid.UUID, username string) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.SetAssignee(reportID, username)
+	})
+}
+
+func (c *ConfigMapReportStorage) SetSnoozedUntil(reportID uuid.UUID, until time.Time) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.SetSnoozedUntil(reportID, until)
+	})
+}
+
+func (c *ConfigMapReportStorage) SubmitReport(reportID uuid.UUID, username string) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.SubmitReport(reportID, username)
+	})
+}
+
+func (c *ConfigMapReportStorage) ReopenReport(reportID uuid.UUID) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.ReopenReport(reportID)
+	})
+}
+
+func (c *ConfigMapReportStorage) SetCount(reportID uuid.UUID, count int32, msg string) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.SetCount(reportID, count, msg)
+	})
+}
+
+func (c *ConfigMapReportStorage) SetNotified(reportID uuid.UUID, count int32, at time.Time) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.SetNotified(reportID, count, at)
+	})
+}
+
+func (c *ConfigMapReportStorage) SetPostID(reportID uuid.UUID, postID string) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.SetPostID(reportID, postID)
+	})
+}
+
+func (c *ConfigMapReportStorage) WriteMute(mute *Mute) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.WriteMute(mute)
+	})
+}
+
+func (c *ConfigMapReportStorage) ReadMutes() ([]*Mute, error) {
+	storage, err := c.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ReadMutes()
+}
+
+func (c *ConfigMapReportStorage) WriteSilence(silence *Silence) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.WriteSilence(silence)
+	})
+}
+
+func (c *ConfigMapReportStorage) ReadSilences() ([]*Silence, error) {
+	storage, err := c.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ReadSilences()
+}
+
+func (c *ConfigMapReportStorage) DeleteSilence(silenceID uuid.UUID) error {
+	return c.update(func(storage *InMemoryReportStorage) error {
+		return storage.DeleteSilence(silenceID)
+	})
+}
@@ -0,0 +1,268 @@
+package reportstorage
+
+import (
+	"github.com/golangee/uuid"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testNamespace = "k8sbot"
+	testName      = "k8sbot-state"
+)
+
+// newFakeClientset returns a clientset, which rejects updates of
+// outdated ConfigMaps with a conflict like the api server does.
+func newFakeClientset() *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	resource := corev1.SchemeGroupVersion.WithResource("configmaps")
+
+	client.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		configMap := action.(k8stesting.UpdateAction).GetObject().(*corev1.ConfigMap).DeepCopy()
+		current, err := client.Tracker().Get(resource, configMap.Namespace, configMap.Name)
+
+		if err != nil {
+			return true, nil, err
+		}
+
+		if current.(*corev1.ConfigMap).ResourceVersion != configMap.ResourceVersion {
+			return true, nil, apierrors.NewConflict(resource.GroupResource(), configMap.Name, nil)
+		}
+
+		version, _ := strconv.Atoi(configMap.ResourceVersion)
+		configMap.ResourceVersion = strconv.Itoa(version + 1)
+
+		return true, configMap, client.Tracker().Update(resource, configMap, configMap.Namespace)
+	})
+
+	return client
+}
+
+// testClock is a clock, which only moves when it is told to.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestStorage(client *fake.Clientset, retention time.Duration, clock *testClock) *ConfigMapReportStorage {
+	storage := NewConfigMapReportStorage(client.CoreV1().ConfigMaps(testNamespace), testName, retention, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	storage.SetClock(clock.Now)
+
+	return storage
+}
+
+func countGets(client *fake.Clientset) int {
+	count := 0
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "get" {
+			count++
+		}
+	}
+
+	return count
+}
+
+func readIDs(t *testing.T, storage ReportStorage) map[uuid.UUID]bool {
+	t.Helper()
+
+	reports, err := storage.ReadAll()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[uuid.UUID]bool{}
+
+	for _, r := range reports {
+		ids[r.ID] = true
+	}
+
+	return ids
+}
+
+func TestConfigMapStorageRetriesOnConflict(t *testing.T) {
+	client := newFakeClientset()
+	clock := &testClock{now: time.Now()}
+	first := newTestStorage(client, time.Hour, clock)
+	second := newTestStorage(client, time.Hour, clock)
+	reports := []*Report{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+
+	if err := first.Write(reports[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second replica caches the ConfigMap, before the first one changes it
+	if ids := readIDs(t, second); len(ids) != 1 {
+		t.Fatalf("expected one report, got %v", ids)
+	}
+
+	if err := first.Write(reports[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := second.Write(reports[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.now = clock.now.Add(cacheTTL)
+	ids := readIDs(t, first)
+
+	for _, r := range reports {
+		if !ids[r.ID] {
+			t.Errorf("expected report %v to be kept, got %v", r.ID, ids)
+		}
+	}
+}
+
+func TestConfigMapStorageCachesReads(t *testing.T) {
+	client := newFakeClientset()
+	clock := &testClock{now: time.Now()}
+	storage := newTestStorage(client, time.Hour, clock)
+	other := newTestStorage(client, time.Hour, clock)
+
+	if err := storage.Write(&Report{ID: uuid.New()}); err != nil {
+		t.Fatal(err)
+	}
+
+	gets := countGets(client)
+
+	// The written ConfigMap is used for the following reads
+	for i := 0; i < 3; i++ {
+		readIDs(t, storage)
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	if n := countGets(client) - gets; n != 0 {
+		t.Fatalf("expected no reads within the ttl, got %v", n)
+	}
+
+	if err := other.Write(&Report{ID: uuid.New()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := readIDs(t, storage); len(ids) != 1 {
+		t.Fatalf("expected the cached report only, got %v", ids)
+	}
+
+	clock.now = clock.now.Add(cacheTTL)
+
+	if ids := readIDs(t, storage); len(ids) != 2 {
+		t.Fatalf("expected the change of the other replica after the ttl, got %v", ids)
+	}
+}
+
+func TestConfigMapStorageDropsSubmittedReportsAfterRetention(t *testing.T) {
+	client := newFakeClientset()
+	clock := &testClock{now: time.Now()}
+	storage := newTestStorage(client, 24*time.Hour, clock)
+	open, submitted, recent := &Report{ID: uuid.New()}, &Report{ID: uuid.New()}, &Report{ID: uuid.New()}
+
+	for _, r := range []*Report{open, submitted, recent} {
+		if err := storage.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := storage.SubmitReport(submitted.ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.now = clock.now.Add(23 * time.Hour)
+
+	if err := storage.SubmitReport(recent.ID, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next write drops the reports after the retention
+	clock.now = clock.now.Add(2 * time.Hour)
+
+	if err := storage.SetCount(open.ID, 2, "again"); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := readIDs(t, storage)
+
+	if len(ids) != 2 || !ids[open.ID] || !ids[recent.ID] {
+		t.Fatalf("expected the open and the recently submitted report, got %v", ids)
+	}
+}
+
+func TestConfigMapStorageDropsOldestSubmittedReportsWhenTooLarge(t *testing.T) {
+	now := time.Now()
+	msg := strings.Repeat("x", maxStateSize/3)
+	open := &Report{ID: uuid.New(), Msg: msg}
+	older := &Report{ID: uuid.New(), Msg: msg, ReportStopped: true, ReportStoppedAt: now.Add(-2 * time.Hour)}
+	newer := &Report{ID: uuid.New(), Msg: msg, ReportStopped: true, ReportStoppedAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name    string
+		reports []*Report
+		kept    []*Report
+		fails   bool
+	}{
+		{
+			name:    "fits",
+			reports: []*Report{open, newer},
+			kept:    []*Report{open, newer},
+		},
+		{
+			name:    "drops the oldest submitted report",
+			reports: []*Report{older, open, newer},
+			kept:    []*Report{open, newer},
+		},
+		{
+			name:    "fails without submitted reports",
+			reports: []*Report{open, {ID: uuid.New(), Msg: msg}, {ID: uuid.New(), Msg: msg}},
+			fails:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClientset()
+			storage := newTestStorage(client, 24*time.Hour, &testClock{now: now})
+			var err error
+
+			for _, r := range tt.reports {
+				if err = storage.Write(r); err != nil {
+					break
+				}
+			}
+
+			if tt.fails {
+				if err == nil {
+					t.Fatal("expected the write to fail")
+				}
+
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := readIDs(t, storage)
+
+			if len(ids) != len(tt.kept) {
+				t.Fatalf("expected %v reports, got %v", len(tt.kept), ids)
+			}
+
+			for _, r := range tt.kept {
+				if !ids[r.ID] {
+					t.Errorf("expected report %v to be kept, got %v", r.ID, ids)
+				}
+			}
+		})
+	}
+}
@@ -3,32 +3,70 @@ package reportstorage
 import (
 	"github.com/golangee/uuid"
 	"k8s.io/apimachinery/pkg/types"
+	"sync"
+	"time"
 )
 
+// InMemoryReportStorage keeps the reports, mutes and silences in
+// memory. It stores and returns copies, so the values can't be
+// changed without the lock by the callers.
 type InMemoryReportStorage struct {
-	reports []*Report
+	mutex    sync.RWMutex
+	reports  []*Report
+	mutes    []*Mute
+	silences []*Silence
+	// now returns the current time, see SetClock.
+	now func() time.Time
 }
 
 func NewInMemoryReportStorage() *InMemoryReportStorage {
 	return &InMemoryReportStorage{
-		reports: []*Report{},
+		reports:  []*Report{},
+		mutes:    []*Mute{},
+		silences: []*Silence{},
+		now:      time.Now,
 	}
 }
 
+// SetClock replaces the clock, which expired mutes and silences
+// are dropped by and reports are submitted at, so recorded
+// events can be replayed in the time they happened.
+func (i *InMemoryReportStorage) SetClock(now func() time.Time) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.now = now
+}
+
 func (i *InMemoryReportStorage) ReadAll() ([]*Report, error) {
-	return i.reports, nil
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	reports := make([]*Report, 0, len(i.reports))
+
+	for _, r := range i.reports {
+		reports = append(reports, copyReport(r))
+	}
+
+	return reports, nil
 }
 
 func (i *InMemoryReportStorage) Write(report *Report) error {
-	i.reports = append(i.reports, report)
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.reports = append(i.reports, copyReport(report))
 
 	return nil
 }
 
 func (i *InMemoryReportStorage) ReadByReportID(reportID uuid.UUID) (*Report, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
 	for _, r := range i.reports {
 		if r.ID == reportID {
-			return r, nil
+			return copyReport(r), nil
 		}
 	}
 
@@ -36,9 +74,25 @@ func (i *InMemoryReportStorage) ReadByReportID(reportID uuid.UUID) (*Report, err
 }
 
 func (i *InMemoryReportStorage) ReadByObjectID(objectID types.UID) (*Report, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
 	for _, r := range i.reports {
 		if r.ReportedObject == objectID {
-			return r, nil
+			return copyReport(r), nil
+		}
+	}
+
+	return nil, &NoReportErr{}
+}
+
+func (i *InMemoryReportStorage) ReadByPostID(postID string) (*Report, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	for _, r := range i.reports {
+		if r.PostID == postID {
+			return copyReport(r), nil
 		}
 	}
 
@@ -46,6 +100,9 @@ func (i *InMemoryReportStorage) ReadByObjectID(objectID types.UID) (*Report, err
 }
 
 func (i *InMemoryReportStorage) Delete(reportID uuid.UUID) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
 	tmp := []*Report{}
 
 	for _, r := range i.reports {
@@ -59,8 +116,10 @@ func (i *InMemoryReportStorage) Delete(reportID uuid.UUID) error {
 	return nil
 }
 
-
 func (i *InMemoryReportStorage) IncreaseCounter(reportID uuid.UUID) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
 	for _, r := range i.reports {
 		if r.ID == reportID {
 			r.ReportTimes++
@@ -71,6 +130,9 @@ func (i *InMemoryReportStorage) IncreaseCounter(reportID uuid.UUID) error {
 }
 
 func (i *InMemoryReportStorage) SetInProgress(reportID uuid.UUID, val bool) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
 	for _, r := range i.reports {
 		if r.ID == reportID {
 			r.IsInProgress = val
@@ -80,11 +142,84 @@ func (i *InMemoryReportStorage) SetInProgress(reportID uuid.UUID, val bool) erro
 	return nil
 }
 
+func (i *InMemoryReportStorage) SetAssignee(reportID uuid.UUID, username string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, r := range i.reports {
+		if r.ID == reportID {
+			r.AssignedTo = username
+		}
+	}
+
+	return nil
+}
+
+func (i *InMemoryReportStorage) SetSnoozedUntil(reportID uuid.UUID, until time.Time) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, r := range i.reports {
+		if r.ID == reportID {
+			r.SnoozedUntil = until
+		}
+	}
+
+	return nil
+}
+
 func (i *InMemoryReportStorage) SubmitReport(reportID uuid.UUID, username string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
 	for _, r := range i.reports {
 		if r.ID == reportID {
 			r.ReportStopped = true
 			r.ReportStoppedBy = username
+			r.ReportStoppedAt = i.now()
+		}
+	}
+
+	return nil
+}
+
+func (i *InMemoryReportStorage) ReopenReport(reportID uuid.UUID) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, r := range i.reports {
+		if r.ID == reportID {
+			r.ReportStopped = false
+			r.ReportStoppedBy = ""
+			r.ReportStoppedAt = time.Time{}
+		}
+	}
+
+	return nil
+}
+
+func (i *InMemoryReportStorage) SetCount(reportID uuid.UUID, count int32, msg string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, r := range i.reports {
+		if r.ID == reportID {
+			r.Count = count
+			r.Msg = msg
+		}
+	}
+
+	return nil
+}
+
+func (i *InMemoryReportStorage) SetNotified(reportID uuid.UUID, count int32, at time.Time) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, r := range i.reports {
+		if r.ID == reportID {
+			r.NotifiedCount = count
+			r.LastReportUpdate = at
 		}
 	}
 
@@ -92,6 +227,9 @@ func (i *InMemoryReportStorage) SubmitReport(reportID uuid.UUID, username string
 }
 
 func (i *InMemoryReportStorage) SetPostID(reportID uuid.UUID, postID string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
 	for _, r := range i.reports {
 		if r.ID == reportID {
 			r.PostID = postID
@@ -100,3 +238,97 @@ func (i *InMemoryReportStorage) SetPostID(reportID uuid.UUID, postID string) err
 
 	return nil
 }
+
+func (i *InMemoryReportStorage) WriteMute(mute *Mute) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	m := *mute
+	i.mutes = append(i.mutes, &m)
+
+	return nil
+}
+
+// ReadMutes returns all mutes which are still active.
+// Expired mutes will be dropped from the storage.
+func (i *InMemoryReportStorage) ReadMutes() ([]*Mute, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	now := i.now()
+	tmp := []*Mute{}
+
+	for _, m := range i.mutes {
+		if m.IsActive(now) {
+			tmp = append(tmp, m)
+		}
+	}
+
+	i.mutes = tmp
+	mutes := make([]*Mute, 0, len(tmp))
+
+	for _, m := range tmp {
+		mute := *m
+		mutes = append(mutes, &mute)
+	}
+
+	return mutes, nil
+}
+
+func (i *InMemoryReportStorage) WriteSilence(silence *Silence) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	s := *silence
+	i.silences = append(i.silences, &s)
+
+	return nil
+}
+
+// ReadSilences returns all silences which are active or start
+// in the future. Expired silences will be dropped from the storage.
+func (i *InMemoryReportStorage) ReadSilences() ([]*Silence, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	now := i.now()
+	tmp := []*Silence{}
+
+	for _, s := range i.silences {
+		if now.Before(s.End) {
+			tmp = append(tmp, s)
+		}
+	}
+
+	i.silences = tmp
+	silences := make([]*Silence, 0, len(tmp))
+
+	for _, s := range tmp {
+		silence := *s
+		silences = append(silences, &silence)
+	}
+
+	return silences, nil
+}
+
+func (i *InMemoryReportStorage) DeleteSilence(silenceID uuid.UUID) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	tmp := []*Silence{}
+
+	for _, s := range i.silences {
+		if s.ID != silenceID {
+			tmp = append(tmp, s)
+		}
+	}
+
+	i.silences = tmp
+
+	return nil
+}
+
+func copyReport(report *Report) *Report {
+	r := *report
+	return &r
+}
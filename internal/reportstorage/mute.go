@@ -0,0 +1,43 @@
+package reportstorage
+
+import "time"
+
+// Mute suppresses new reports for the given
+// event reason until the mute expires.
+type Mute struct {
+	Reason    string
+	Namespace string // When empty, the reason is muted in all namespaces
+	Until     time.Time
+	MutedBy   string
+}
+
+// Matches reports whether the mute applies
+// to the given namespace and reason.
+func (m *Mute) Matches(namespace, reason string) bool {
+	return m.Reason == reason && (m.Namespace == "" || m.Namespace == namespace)
+}
+
+// IsActive reports whether the mute is still
+// suppressing reports at the given time.
+func (m *Mute) IsActive(now time.Time) bool {
+	return now.Before(m.Until)
+}
+
+// FindMute returns the mute of the storage, which is active at the
+// given time, for the namespace and reason. When nothing is muted,
+// nil is returned.
+func FindMute(storage ReportStorage, now time.Time, namespace, reason string) (*Mute, error) {
+	mutes, err := storage.ReadMutes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mute := range mutes {
+		if mute.Matches(namespace, reason) && mute.IsActive(now) {
+			return mute, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -3,6 +3,7 @@ package reportstorage
 import (
 	"github.com/golangee/uuid"
 	"k8s.io/apimachinery/pkg/types"
+	"k8sbot/internal/logging"
 	"time"
 )
 
@@ -15,9 +16,30 @@ type Report struct {
 	Resource         string
 	Msg              string
 	Count            int32 // Num how often the issue happens in the cluster
+	NotifiedCount    int32 // Count at the time of the last notification
 	ReportTimes      int   // How often the same issue was reported
 	IsInProgress     bool  // Is used, to check if a maintainer checks the issue
-	LastReportUpdate time.Time
+	AssignedTo       string
+	SnoozedUntil     time.Time // The report won't be updated until then
+	LastReportUpdate time.Time // Time of the last notification
+	CreatedAt        time.Time
 	ReportStopped    bool
 	ReportStoppedBy  string
+	ReportStoppedAt  time.Time
+}
+
+// IsSnoozed reports whether the report is
+// snoozed at the given time.
+func (r *Report) IsSnoozed(now time.Time) bool {
+	return now.Before(r.SnoozedUntil)
+}
+
+// LogAttrs returns the fields, which identify
+// the report in the log.
+func (r *Report) LogAttrs() []any {
+	return []any{
+		logging.ReportID, r.ID.String(),
+		logging.Namespace, r.Namespace,
+		logging.Reason, r.Reason,
+	}
 }
@@ -0,0 +1,82 @@
+package reportstorage
+
+import (
+	"fmt"
+	"github.com/golangee/uuid"
+	"regexp"
+	"time"
+)
+
+// Silence suppresses new reports during a maintenance window.
+// Empty fields match everything, the object is a regular
+// expression, which has to match the whole object name.
+type Silence struct {
+	ID        uuid.UUID `json:"id"`
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Reason    string    `json:"reason"`
+	Object    string    `json:"object"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	CreatedBy string    `json:"created_by"`
+	Comment   string    `json:"comment"`
+
+	object *regexp.Regexp
+}
+
+// Validate checks the time range and compiles the object
+// expression. It has to be called before Matches is used.
+func (s *Silence) Validate() error {
+	if s.End.IsZero() || !s.End.After(s.Start) {
+		return fmt.Errorf("the end of the silence must be after its start")
+	}
+
+	if s.Object == "" {
+		s.object = nil
+		return nil
+	}
+
+	object, err := regexp.Compile("^(?:" + s.Object + ")$")
+
+	if err != nil {
+		return fmt.Errorf("invalid object expression %v: %w", s.Object, err)
+	}
+
+	s.object = object
+
+	return nil
+}
+
+// Matches reports whether the silence applies to the
+// report of the given object.
+func (s *Silence) Matches(cluster, namespace, reason, resource string) bool {
+	return (s.Cluster == "" || s.Cluster == cluster) &&
+		(s.Namespace == "" || s.Namespace == namespace) &&
+		(s.Reason == "" || s.Reason == reason) &&
+		(s.object == nil || s.object.MatchString(resource))
+}
+
+// IsActive reports whether the silence is suppressing
+// reports at the given time.
+func (s *Silence) IsActive(now time.Time) bool {
+	return !now.Before(s.Start) && now.Before(s.End)
+}
+
+// FindSilence returns the silence of the storage, which is active at
+// the given time, for the object. When nothing is silenced, nil is
+// returned.
+func FindSilence(storage ReportStorage, now time.Time, cluster, namespace, reason, resource string) (*Silence, error) {
+	silences, err := storage.ReadSilences()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, silence := range silences {
+		if silence.IsActive(now) && silence.Matches(cluster, namespace, reason, resource) {
+			return silence, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,117 @@
+package reportstorage
+
+import (
+	"github.com/golangee/uuid"
+	"testing"
+	"time"
+)
+
+func TestSilenceMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		silence Silence
+		matches bool
+	}{
+		{name: "empty fields match everything", silence: Silence{}, matches: true},
+		{name: "same cluster", silence: Silence{Cluster: "prod"}, matches: true},
+		{name: "other cluster", silence: Silence{Cluster: "staging"}},
+		{name: "same namespace", silence: Silence{Namespace: "default"}, matches: true},
+		{name: "other namespace", silence: Silence{Namespace: "kube-system"}},
+		{name: "same reason", silence: Silence{Reason: "BackOff"}, matches: true},
+		{name: "other reason", silence: Silence{Reason: "FailedMount"}},
+		{name: "object expression", silence: Silence{Object: "api-.*"}, matches: true},
+		{name: "object expression is anchored at the start", silence: Silence{Object: "pi-.*"}},
+		{name: "object expression is anchored at the end", silence: Silence{Object: "api"}},
+		{name: "object alternatives", silence: Silence{Object: "worker|api-7d9"}, matches: true},
+		{name: "all fields", silence: Silence{Cluster: "prod", Namespace: "default", Reason: "BackOff", Object: "api-[0-9a-z]+"}, matches: true},
+		{name: "one field differs", silence: Silence{Cluster: "prod", Namespace: "default", Reason: "FailedMount", Object: "api-[0-9a-z]+"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			silence := tt.silence
+			silence.End = time.Now().Add(time.Hour)
+
+			if err := silence.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			if matches := silence.Matches("prod", "default", "BackOff", "api-7d9"); matches != tt.matches {
+				t.Fatalf("expected matches %v, got %v", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestSilenceValidate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		silence Silence
+		valid   bool
+	}{
+		{name: "valid", silence: Silence{Start: now, End: now.Add(time.Hour)}, valid: true},
+		{name: "without start", silence: Silence{End: now.Add(time.Hour)}, valid: true},
+		{name: "without end", silence: Silence{Start: now}},
+		{name: "end before start", silence: Silence{Start: now, End: now.Add(-time.Hour)}},
+		{name: "end at start", silence: Silence{Start: now, End: now}},
+		{name: "invalid object expression", silence: Silence{Start: now, End: now.Add(time.Hour), Object: "api-("}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.silence.Validate(); (err == nil) != tt.valid {
+				t.Fatalf("expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestFindSilence(t *testing.T) {
+	now := time.Now()
+	storage := NewInMemoryReportStorage()
+	storage.SetClock(func() time.Time { return now })
+	silences := map[string]*Silence{
+		"past":   {ID: uuid.New(), Namespace: "past", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+		"future": {ID: uuid.New(), Namespace: "future", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)},
+		"active": {ID: uuid.New(), Namespace: "active", Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+	}
+
+	for _, s := range silences {
+		if err := s.Validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := storage.WriteSilence(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		namespace string
+		at        time.Time
+		want      *Silence
+	}{
+		{namespace: "active", at: now, want: silences["active"]},
+		{namespace: "active", at: now.Add(time.Hour)},
+		{namespace: "future", at: now},
+		{namespace: "future", at: now.Add(time.Hour), want: silences["future"]},
+		{namespace: "past", at: now.Add(-90 * time.Minute)},
+		{namespace: "default", at: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			found, err := FindSilence(storage, tt.at, "", tt.namespace, "BackOff", "api")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (found == nil) != (tt.want == nil) || (found != nil && found.ID != tt.want.ID) {
+				t.Fatalf("expected %+v, got %+v", tt.want, found)
+			}
+		})
+	}
+}
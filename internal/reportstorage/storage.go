@@ -3,6 +3,7 @@ package reportstorage
 import (
 	"github.com/golangee/uuid"
 	"k8s.io/apimachinery/pkg/types"
+	"time"
 )
 
 type ReportStorage interface {
@@ -10,11 +11,24 @@ type ReportStorage interface {
 	Write(report *Report) error
 	ReadByReportID(reportID uuid.UUID) (*Report, error)
 	ReadByObjectID(objectID types.UID) (*Report, error)
+	ReadByPostID(postID string) (*Report, error)
 	Delete(reportID uuid.UUID) error
 
 	IncreaseCounter(reportID uuid.UUID) error
 	SetInProgress(reportID uuid.UUID, val bool) error
+	SetAssignee(reportID uuid.UUID, username string) error
+	SetSnoozedUntil(reportID uuid.UUID, until time.Time) error
 	SubmitReport(reportID uuid.UUID, username string) error
+	ReopenReport(reportID uuid.UUID) error
+	SetCount(reportID uuid.UUID, count int32, msg string) error
+	SetNotified(reportID uuid.UUID, count int32, at time.Time) error
 
 	SetPostID(reportID uuid.UUID, postID string) error
+
+	WriteMute(mute *Mute) error
+	ReadMutes() ([]*Mute, error)
+
+	WriteSilence(silence *Silence) error
+	ReadSilences() ([]*Silence, error)
+	DeleteSilence(silenceID uuid.UUID) error
 }
@@ -1,26 +1,80 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"io"
+	"k8sbot/internal/actiontoken"
 	"k8sbot/internal/configuration"
+	"k8sbot/internal/election"
 	"k8sbot/internal/eventctx"
 	"k8sbot/internal/http"
 	"k8sbot/internal/k8s"
 	"k8sbot/internal/listener"
+	"k8sbot/internal/logging"
 	"k8sbot/internal/mattermost"
+	"k8sbot/internal/metrics"
+	"k8sbot/internal/notifier"
+	"k8sbot/internal/replay"
+	"k8sbot/internal/reporter"
 	"k8sbot/internal/reportstorage"
+	"log/slog"
 	http2 "net/http"
+	"os"
+	"time"
 )
 
+// actionTokenValidity is the time, a button of a post stays
+// usable after the post was rendered the last time.
+const actionTokenValidity = 7 * 24 * time.Hour
+
+// defaultRateLimit is the maximum number of notifications
+// per minute and notifier, when no rate_limit is configured.
+const defaultRateLimit = 30
+
+const defaultListenAddress = ":9090"
+
+// defaultRetention is the time, submitted reports stay in
+// the configmap storage, when no retention is configured.
+const defaultRetention = 7 * 24 * time.Hour
+
+// configuredSilence is the creator of the silences of the configuration.
+const configuredSilence = "config"
+
 type Server struct {
 	config            *configuration.Configuration
 	endpoints         *http.ReportEndpoints
+	commandEndpoints  *http.CommandEndpoints
+	alertEndpoints    *http.AlertmanagerEndpoints
+	silenceEndpoints  *http.SilenceEndpoints
+	apiReports        *http.ApiReportEndpoints
+	dashboard         *http.DashboardEndpoints
+	health            *http.HealthEndpoints
+	apiRouter         *mux.Router
+	reportStorage     reportstorage.ReportStorage
 	mattermostHandler *mattermost.MattermostHandler
+	channelResolver   *mattermost.ChannelResolver
+	webSocketListener *mattermost.WebSocketListener
+	notifier          notifier.Notifier
+	queues            []*notifier.Queue
+	emailNotifier     *notifier.EmailNotifier
+	printer           *notifier.Printer
+	reporter          *reporter.Reporter
 	k8sApi            *k8s.KubernetesApi
 	client            *model.Client4
 	botUser           *model.User
 	listeners         []listener.Listener
+	supervisor        *listener.Supervisor
+	elector           *election.Elector
+	httpServer        *http2.Server
+	serveMux          *http2.ServeMux
+	logger            *slog.Logger
+	// replaying is set by Replay, the notifiers aren't queued then.
+	replaying bool
 }
 
 func NewServer() *Server {
@@ -37,12 +91,200 @@ func (s *Server) getReportEndpoints() (*http.ReportEndpoints, error) {
 			return nil, err
 		}
 
-		s.endpoints = http.NewReportEndpoints(handler)
+		reporter, err := s.getReporter()
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.endpoints = http.NewReportEndpoints(s.getServeMux(), handler, reporter, s.getReportStorage(), s.getLogger())
 	}
 
 	return s.endpoints, nil
 }
 
+func (s *Server) getCommandEndpoints() (*http.CommandEndpoints, error) {
+	if s.commandEndpoints == nil {
+		reporter, err := s.getReporter()
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.commandEndpoints = http.NewCommandEndpoints(s.getServeMux(), reporter, s.getReportStorage(), s.config.SlashCommandToken, s.getLogger())
+	}
+
+	return s.commandEndpoints, nil
+}
+
+func (s *Server) getAlertmanagerEndpoints() (*http.AlertmanagerEndpoints, error) {
+	if s.alertEndpoints == nil {
+		reporter, err := s.getReporter()
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.alertEndpoints = http.NewAlertmanagerEndpoints(s.getServeMux(), reporter, s.config.AlertmanagerToken, s.getLogger())
+	}
+
+	return s.alertEndpoints, nil
+}
+
+func (s *Server) getApiRouter() *mux.Router {
+	if s.apiRouter == nil {
+		s.apiRouter = http.NewApiRouter(s.getServeMux(), s.config.ApiToken, s.getLogger())
+	}
+
+	return s.apiRouter
+}
+
+func (s *Server) getSilenceEndpoints() (*http.SilenceEndpoints, error) {
+	if s.silenceEndpoints == nil {
+		reporter, err := s.getReporter()
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.silenceEndpoints = http.NewSilenceEndpoints(s.getApiRouter(), reporter, s.getReportStorage(), s.getLogger())
+	}
+
+	return s.silenceEndpoints, nil
+}
+
+func (s *Server) getApiReportEndpoints() (*http.ApiReportEndpoints, error) {
+	if s.apiReports == nil {
+		reporter, err := s.getReporter()
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.apiReports = http.NewApiReportEndpoints(s.getApiRouter(), reporter, s.getReportStorage(), s.getLogger())
+	}
+
+	return s.apiReports, nil
+}
+
+func (s *Server) getDashboardEndpoints() *http.DashboardEndpoints {
+	if s.dashboard == nil {
+		s.dashboard = http.NewDashboardEndpoints(s.getServeMux(), s.getReportStorage(), s.config.ApiToken, s.config.MattermostHost, s.config.TeamID, s.getLogger())
+	}
+
+	return s.dashboard
+}
+
+func (s *Server) getHealthEndpoints() (*http.HealthEndpoints, error) {
+	if s.health == nil {
+		listeners, err := s.getListeners()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot get listeners: %w", err)
+		}
+
+		s.health = http.NewHealthEndpoints(s.getServeMux(), listeners, s.checkMattermost, s.getLogger())
+	}
+
+	return s.health, nil
+}
+
+// checkMattermost reports whether the mattermost server is reachable.
+func (s *Server) checkMattermost() error {
+	if s.config.DryRun {
+		return nil
+	}
+
+	if _, resp := s.getMattermostClient().GetPing(); resp.Error != nil {
+		return fmt.Errorf("cannot reach mattermost: %w", resp.Error)
+	}
+
+	return nil
+}
+
+func (s *Server) getLogger() *slog.Logger {
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+
+	return s.logger
+}
+
+// initLogger creates the configured logger. It has to be called
+// before the first use of getLogger, which falls back to the default
+// logger. The standard log package writes to the logger as well.
+func (s *Server) initLogger() error {
+	logger, err := logging.New(os.Stderr, s.config.LogLevel, s.config.LogFormat)
+
+	if err != nil {
+		return err
+	}
+
+	if s.config.ClusterName != "" {
+		logger = logger.With(logging.Cluster, s.config.ClusterName)
+	}
+
+	slog.SetDefault(logger)
+	s.logger = logger
+
+	return nil
+}
+
+func (s *Server) getReportStorage() reportstorage.ReportStorage {
+	if s.reportStorage == nil {
+		s.reportStorage = reportstorage.NewInMemoryReportStorage()
+	}
+
+	return s.reportStorage
+}
+
+// initReportStorage creates the configured storage. It has to be
+// called before the first use of getReportStorage, which falls
+// back to the in-memory storage.
+func (s *Server) initReportStorage() error {
+	if s.config.DryRun {
+		s.getLogger().Info("the reports of the dry run are kept in memory")
+		return nil
+	}
+
+	switch s.config.Storage.Type {
+	case "", "memory":
+		if s.config.LeaderElection.Enabled {
+			s.getLogger().Warn("the replicas don't share their reports, because the storage is in memory")
+		}
+
+		return nil
+	case "configmap":
+		k8sApi, err := s.getKubernetesApi()
+
+		if err != nil {
+			return fmt.Errorf("cannot get kubernetes api: %w", err)
+		}
+
+		if s.config.Storage.Namespace == "" {
+			return fmt.Errorf("the configmap storage needs a namespace")
+		}
+
+		name := s.config.Storage.Name
+
+		if name == "" {
+			name = "k8sbot-reports"
+		}
+
+		retention := time.Duration(s.config.Storage.RetentionDays) * 24 * time.Hour
+
+		if retention <= 0 {
+			retention = defaultRetention
+		}
+
+		s.reportStorage = reportstorage.NewConfigMapReportStorage(k8sApi.CoreV1().ConfigMaps(s.config.Storage.Namespace), name, retention, s.getLogger())
+
+		return nil
+	default:
+		return fmt.Errorf("unknown storage type %v", s.config.Storage.Type)
+	}
+}
+
 func (s *Server) getMattermostHandler() (*mattermost.MattermostHandler, error) {
 	if s.mattermostHandler == nil {
 		user, err := s.getBotUser()
@@ -51,26 +293,316 @@ func (s *Server) getMattermostHandler() (*mattermost.MattermostHandler, error) {
 			return nil, fmt.Errorf("cannot get bot user: %w", err)
 		}
 
-		s.mattermostHandler = mattermost.NewMattermostHandler(s.config.BotWantedUsername, user, s.getMattermostClient(), s.config.MaintainerUsernames, s.config.DevOpsChannel, s.config.TeamID, reportstorage.NewInMemoryReportStorage())
+		if s.config.ActionSecret == "" {
+			return nil, fmt.Errorf("action_secret must be set to sign the post actions")
+		}
+
+		channels, err := s.getChannelResolver()
+
+		if err != nil {
+			return nil, err
+		}
+
+		signer := actiontoken.NewSigner(s.config.ActionSecret, actionTokenValidity)
+
+		s.mattermostHandler = mattermost.NewMattermostHandler(user, s.getMattermostClient(), s.config.MaintainerUsernames, channels, s.config.PublicURL, signer, s.getReportStorage(), s.getLogger())
 	}
 
 	return s.mattermostHandler, nil
 }
 
+// getChannelResolver returns the resolver of the dev-ops channel.
+// The team and the channel are validated once, so a wrong
+// configuration fails at the start of the bot.
+func (s *Server) getChannelResolver() (*mattermost.ChannelResolver, error) {
+	if s.channelResolver == nil {
+		if _, err := s.getBotUser(); err != nil {
+			return nil, fmt.Errorf("cannot get bot user: %w", err)
+		}
+
+		channels := mattermost.NewChannelResolver(s.getMattermostClient(), s.config.TeamID, s.config.DevOpsChannel)
+
+		if err := channels.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid mattermost configuration: %w", err)
+		}
+
+		s.channelResolver = channels
+	}
+
+	return s.channelResolver, nil
+}
+
+func (s *Server) getWebSocketListener() (*mattermost.WebSocketListener, error) {
+	if s.webSocketListener == nil {
+		user, err := s.getBotUser()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot get bot user: %w", err)
+		}
+
+		reporter, err := s.getReporter()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot get reporter: %w", err)
+		}
+
+		s.webSocketListener = mattermost.NewWebSocketListener(s.config.MattermostHost, s.config.ClientToken, user, s.getMattermostClient(), s.getReportStorage(), reporter, s.getLogger())
+	}
+
+	return s.webSocketListener, nil
+}
+
+// getNotifier returns a router, which sends every report to the
+// notifier of its route. Reports without a matching route and
+// internal errors are sent to mattermost.
+func (s *Server) getNotifier() (notifier.Notifier, error) {
+	if s.notifier == nil {
+		mattermostNotifier, err := s.getMattermostNotifier()
+
+		if err != nil {
+			return nil, err
+		}
+
+		notifiers := map[string]notifier.Notifier{
+			"mattermost": s.deliver("mattermost", mattermostNotifier, 0),
+		}
+
+		if email, err := s.getEmailNotifier(); err != nil {
+			return nil, err
+		} else if email != nil {
+			notifiers["email"] = s.deliver("email", s.dryRun("email", email), 0)
+		}
+
+		for _, c := range s.config.Notifiers {
+			if _, ok := notifiers[c.Name]; ok {
+				return nil, fmt.Errorf("notifier %v is configured twice", c.Name)
+			}
+
+			var n notifier.Notifier
+
+			switch c.Type {
+			case "slack":
+				n = notifier.NewSlackNotifier(c.URL, s.getLogger())
+			case "teams":
+				n = notifier.NewTeamsNotifier(c.URL, s.getLogger())
+			case "webhook":
+				n = notifier.NewWebhookNotifier(c.URL, c.Headers, s.getLogger())
+			case "alertmanager":
+				n = notifier.NewAlertmanagerNotifier(c.URL, c.Headers, s.getLogger())
+			default:
+				return nil, fmt.Errorf("notifier %v has unknown type %v", c.Name, c.Type)
+			}
+
+			notifiers[c.Name] = s.deliver(c.Name, s.dryRun(c.Name, n), c.RateLimit)
+		}
+
+		routes := []*notifier.Route{}
+
+		for _, c := range s.config.Routes {
+			n, ok := notifiers[c.Notifier]
+
+			if !ok {
+				return nil, fmt.Errorf("route uses unknown notifier %v", c.Notifier)
+			}
+
+			routes = append(routes, &notifier.Route{
+				Namespaces: c.Namespaces,
+				Reasons:    c.Reasons,
+				Notifier:   n,
+			})
+		}
+
+		s.notifier = notifier.NewRouter(notifiers["mattermost"], routes)
+	}
+
+	return s.notifier, nil
+}
+
+// getMattermostNotifier returns the handler, which posts the reports.
+// In dry-run mode, the posts are rendered and printed instead.
+func (s *Server) getMattermostNotifier() (notifier.Notifier, error) {
+	if s.config.DryRun {
+		signer := actiontoken.NewSigner(s.config.ActionSecret, actionTokenValidity)
+
+		return mattermost.NewDryRunNotifier(s.config.PublicURL, signer, s.getReportStorage(), s.getPrinter()), nil
+	}
+
+	handler, err := s.getMattermostHandler()
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot get mattermost handler: %w", err)
+	}
+
+	return handler, nil
+}
+
+// dryRun replaces the notifier with one, which prints the
+// notifications, when the bot runs in dry-run mode.
+func (s *Server) dryRun(name string, n notifier.Notifier) notifier.Notifier {
+	if !s.config.DryRun {
+		return n
+	}
+
+	return notifier.NewPrintNotifier(name, s.getPrinter())
+}
+
+func (s *Server) getPrinter() *notifier.Printer {
+	if s.printer == nil {
+		s.printer = notifier.NewPrinter(os.Stdout)
+	}
+
+	return s.printer
+}
+
+// deliver returns the notifier, which delivers the notifications of
+// the sink. A replay prints them immediately and in order, otherwise
+// they are queued.
+func (s *Server) deliver(name string, n notifier.Notifier, rateLimit int) notifier.Notifier {
+	if s.replaying {
+		return n
+	}
+
+	return s.newQueue(name, n, rateLimit)
+}
+
+// newQueue wraps the notifier into a queue, so the notifications
+// are delivered in the background. Without rate limit, the
+// configured or the default rate limit is used.
+func (s *Server) newQueue(name string, n notifier.Notifier, rateLimit int) *notifier.Queue {
+	if rateLimit <= 0 {
+		rateLimit = s.config.RateLimit
+	}
+
+	if rateLimit <= 0 {
+		rateLimit = defaultRateLimit
+	}
+
+	queue := notifier.NewQueue(name, n, notifier.NewInMemoryJobStore(), s.getReportStorage(), rateLimit, s.getLogger())
+	s.queues = append(s.queues, queue)
+
+	return queue
+}
+
+// getEmailNotifier returns the email notifier, when a smtp
+// host is configured. Otherwise nil will be returned.
+func (s *Server) getEmailNotifier() (*notifier.EmailNotifier, error) {
+	if s.emailNotifier == nil && s.config.Smtp.Host != "" {
+		var digestInterval time.Duration
+
+		switch s.config.Smtp.Digest {
+		case "":
+			digestInterval = 0
+		case "hourly":
+			digestInterval = time.Hour
+		case "daily":
+			digestInterval = 24 * time.Hour
+		default:
+			return nil, fmt.Errorf("invalid smtp digest %v", s.config.Smtp.Digest)
+		}
+
+		smtp := s.config.Smtp
+
+		s.emailNotifier = notifier.NewEmailNotifier(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From, s.config.MaintainerEmails, digestInterval, s.getLogger())
+	}
+
+	return s.emailNotifier, nil
+}
+
+func (s *Server) getReporter() (*reporter.Reporter, error) {
+	if s.reporter == nil {
+		n, err := s.getNotifier()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot get notifier: %w", err)
+		}
+
+		s.reporter = reporter.NewReporter(s.getReportStorage(), n, s.getFollowUpInterval(), s.config.StormThreshold, s.config.ClusterName, s.getLogger())
+	}
+
+	return s.reporter, nil
+}
+
+// getFollowUpInterval returns the configured follow-up interval.
+// When nothing is configured, 15 minutes are used.
+func (s *Server) getFollowUpInterval() time.Duration {
+	if s.config.FollowUpInterval <= 0 {
+		return 15 * time.Minute
+	}
+
+	return time.Duration(s.config.FollowUpInterval) * time.Minute
+}
+
+func (s *Server) getShutdownTimeout() time.Duration {
+	if s.config.ShutdownTimeout <= 0 {
+		return 30 * time.Second
+	}
+
+	return time.Duration(s.config.ShutdownTimeout) * time.Second
+}
+
+func (s *Server) getHttpServer() *http2.Server {
+	if s.httpServer == nil {
+		addr := s.config.ListenAddress
+
+		if addr == "" {
+			addr = defaultListenAddress
+		}
+
+		s.httpServer = &http2.Server{
+			Addr:              addr,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			Handler:           s.getServeMux(),
+		}
+	}
+
+	return s.httpServer
+}
+
+// getServeMux returns the mux, all endpoints are registered at.
+// The default mux isn't used, so no package can add endpoints.
+func (s *Server) getServeMux() *http2.ServeMux {
+	if s.serveMux == nil {
+		s.serveMux = http2.NewServeMux()
+	}
+
+	return s.serveMux
+}
+
 func (s *Server) getMattermostClient() *model.Client4 {
 	if s.client == nil {
 		s.client = model.NewAPIv4Client(s.config.MattermostHost)
+		s.client.HttpClient = metrics.NewInstrumentedClient()
 	}
 
 	return s.client
 }
 
+// getBotUser authenticates with the configured access token.
 func (s *Server) getBotUser() (*model.User, error) {
 	if s.botUser == nil {
-		user, resp := s.getMattermostClient().Login(s.config.BotUsername, s.config.BotPassword)
+		if s.config.ClientToken == "" {
+			return nil, fmt.Errorf("client_token must be set to the access token of the bot")
+		}
+
+		client := s.getMattermostClient()
+		client.SetToken(s.config.ClientToken)
+
+		user, resp := client.GetMe("")
+
+		switch {
+		case resp.StatusCode == http2.StatusUnauthorized:
+			return nil, fmt.Errorf("the client_token is invalid or expired: %w", resp.Error)
+		case resp.StatusCode == http2.StatusForbidden:
+			return nil, fmt.Errorf("the client_token lacks the permission to read its own user: %w", resp.Error)
+		case resp.Error != nil:
+			return nil, fmt.Errorf("cannot get user of the client_token: %w", resp.Error)
+		}
 
-		if resp.Error != nil {
-			return nil, fmt.Errorf("cannot login to user: %w", resp.Error)
+		if !user.IsBot {
+			s.getLogger().Warn("the client_token belongs to a regular user, a bot account is recommended", logging.User, user.Username)
 		}
 
 		s.botUser = user
@@ -97,10 +629,10 @@ func (s *Server) getListeners() ([]listener.Listener, error) {
 	if s.listeners == nil {
 		s.listeners = []listener.Listener{}
 
-		handler, err := s.getMattermostHandler()
+		reporter, err := s.getReporter()
 
 		if err != nil {
-			return nil, fmt.Errorf("cannot get mattermost handler: %w", err)
+			return nil, fmt.Errorf("cannot get reporter: %w", err)
 		}
 
 		k8sApi, err := s.getKubernetesApi()
@@ -109,54 +641,365 @@ func (s *Server) getListeners() ([]listener.Listener, error) {
 			return nil, fmt.Errorf("cannot get kubernetes api: %w", err)
 		}
 
-		s.listeners = append(s.listeners, eventctx.NewEventListener(handler, k8sApi, s.config.WarnOnEventReasons, s.config.WarnOnReachCount))
+		s.listeners = append(s.listeners, eventctx.NewEventListener(reporter, k8sApi, s.config.WarnOnEventReasons, s.config.WarnOnReachCount, s.getLogger()))
 	}
 
 	return s.listeners, nil
 }
 
-func (s *Server) Start() error {
-	// Init the bot
-	if _, err := s.getBotUser(); err != nil {
-		return fmt.Errorf("init bot failed: %w", err)
+func (s *Server) getSupervisor() (*listener.Supervisor, error) {
+	if s.supervisor == nil {
+		listeners, err := s.getListeners()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot get listeners: %w", err)
+		}
+
+		reporter, err := s.getReporter()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot get reporter: %w", err)
+		}
+
+		s.supervisor = listener.NewSupervisor(listeners, reporter.SendInternalError, s.getLogger())
+	}
+
+	return s.supervisor, nil
+}
+
+func (s *Server) getElector() (*election.Elector, error) {
+	if s.elector == nil {
+		k8sApi, err := s.getKubernetesApi()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot get kubernetes api: %w", err)
+		}
+
+		config := s.config.LeaderElection
+
+		if config.Namespace == "" {
+			return nil, fmt.Errorf("the leader election needs a namespace")
+		}
+
+		if config.LeaseName == "" {
+			config.LeaseName = "k8sbot-leader"
+		}
+
+		if config.Identity == "" {
+			hostname, err := os.Hostname()
+
+			if err != nil {
+				return nil, fmt.Errorf("cannot get identity for leader election: %w", err)
+			}
+
+			config.Identity = hostname
+		}
+
+		s.elector = election.NewElector(k8sApi, config.Namespace, config.LeaseName, config.Identity, s.getLogger())
+	}
+
+	return s.elector, nil
+}
+
+// addConfiguredSilences replaces the silences of the previous
+// start with the configured ones, so they aren't duplicated
+// in a persistent storage.
+func (s *Server) addConfiguredSilences() error {
+	reporter, err := s.getReporter()
+
+	if err != nil {
+		return err
+	}
+
+	silences, err := s.getReportStorage().ReadSilences()
+
+	if err != nil {
+		return fmt.Errorf("cannot read silences: %w", err)
+	}
+
+	for _, silence := range silences {
+		if silence.CreatedBy == configuredSilence {
+			if err := reporter.DeleteSilence(silence.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, c := range s.config.Silences {
+		if err := reporter.AddSilence(&reportstorage.Silence{
+			Cluster:   c.Cluster,
+			Namespace: c.Namespace,
+			Reason:    c.Reason,
+			Object:    c.Object,
+			Start:     c.Start,
+			End:       c.End,
+			CreatedBy: configuredSilence,
+			Comment:   c.Comment,
+		}); err != nil {
+			return fmt.Errorf("cannot add configured silence: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// lead runs the parts of the bot, which only the leader runs: the
+// listeners, the websocket and the follow-ups. They stop, when the
+// context is done, e.g. because the leadership was lost.
+func (s *Server) lead(ctx context.Context) {
+	supervisor, err := s.getSupervisor()
+
+	if err != nil {
+		s.getLogger().Error("cannot lead", logging.Err(err))
+		return
+	}
+
+	reporter, err := s.getReporter()
+
+	if err != nil {
+		s.getLogger().Error("cannot lead", logging.Err(err))
+		return
+	}
+
+	supervisor.Start(ctx)
+
+	if err := reporter.Listen(ctx); err != nil {
+		reporter.SendInternalError(fmt.Errorf("cannot follow up reports: %w", err))
+	}
+
+	if s.config.DryRun {
+		return
+	}
+
+	webSocket, err := s.getWebSocketListener()
+
+	if err == nil {
+		err = webSocket.Listen(ctx)
+	}
+
+	if err != nil {
+		reporter.SendInternalError(fmt.Errorf("cannot listen to mattermost websocket: %w", err))
+	}
+}
+
+// Start runs the bot until the context is done. Then the http
+// server and the listeners are stopped and the queued notifications
+// are delivered within the shutdown timeout.
+func (s *Server) Start(ctx context.Context) error {
+	if err := s.initLogger(); err != nil {
+		return fmt.Errorf("cannot init logger: %w", err)
+	}
+
+	// Init the bot, a dry run doesn't need mattermost
+	if !s.config.DryRun {
+		if _, err := s.getBotUser(); err != nil {
+			return fmt.Errorf("init bot failed: %w", err)
+		}
+
+		if _, err := s.getChannelResolver(); err != nil {
+			return fmt.Errorf("init bot failed: %w", err)
+		}
 	}
 
 	if _, err := s.getKubernetesApi(); err != nil {
 		return fmt.Errorf("inti k8s-api failed: %w", err)
 	}
 
-	listeners, err := s.getListeners()
+	if err := s.initReportStorage(); err != nil {
+		return fmt.Errorf("cannot init report storage: %w", err)
+	}
+
+	// The supervisor and the websocket listener are created up front, so
+	// a follower fails early with an invalid configuration as well.
+	if _, err := s.getSupervisor(); err != nil {
+		return err
+	}
+
+	if !s.config.DryRun {
+		if _, err := s.getWebSocketListener(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.addConfiguredSilences(); err != nil {
+		return err
+	}
+
+	for _, q := range s.queues {
+		if err := q.Listen(ctx); err != nil {
+			return fmt.Errorf("cannot deliver notifications: %w", err)
+		}
+	}
+
+	// A dry run always leads, so it doesn't take over from the bot
+	if s.config.LeaderElection.Enabled && !s.config.DryRun {
+		elector, err := s.getElector()
+
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := elector.Run(ctx, s.lead); err != nil {
+				s.getLogger().Error("cannot elect leader", logging.Err(err))
+			}
+		}()
+	} else {
+		metrics.Leader.Set(1)
+		s.lead(ctx)
+	}
+
+	email, err := s.getEmailNotifier()
 
 	if err != nil {
-		return fmt.Errorf("cannot get listeners: %w", err)
+		return err
 	}
 
-	done := make(chan bool)
+	if email != nil {
+		if err := email.Listen(ctx); err != nil {
+			return fmt.Errorf("cannot send email digests: %w", err)
+		}
+	}
 
-	for i, l := range listeners {
-		if err := l.Listen(done); err != nil {
-			return fmt.Errorf("cannot start l %v: %w", i, err)
+	// The actions of the posts need mattermost
+	if !s.config.DryRun {
+		if _, err := s.getReportEndpoints(); err != nil {
+			return err
 		}
 	}
 
-	handler, err := s.getMattermostHandler()
+	_, err = s.getCommandEndpoints()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.getAlertmanagerEndpoints()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.getSilenceEndpoints()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.getApiReportEndpoints()
+
+	if err != nil {
+		return err
+	}
+
+	s.getDashboardEndpoints()
+
+	_, err = s.getHealthEndpoints()
 
 	if err != nil {
 		return err
 	}
 
-	if err := handler.Listen(done); err != nil {
-		return fmt.Errorf("cannot check reports: %w", err)
+	s.getServeMux().Handle("/metrics", promhttp.Handler())
+
+	server := s.getHttpServer()
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	s.getLogger().Info("listening", "address", server.Addr)
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("cannot serve http: %w", err)
+	case <-ctx.Done():
+	}
+
+	s.getLogger().Info("shutting down")
+
+	return s.shutdown()
+}
+
+// Replay feeds the recorded events of the reader through the event
+// listener and the reporter with the configured reasons, thresholds,
+// routes and silences. Like in dry-run mode, the notifications are
+// printed instead of sent. Nothing else of the bot is started.
+func (s *Server) Replay(reader io.Reader) error {
+	s.config.DryRun = true
+	s.replaying = true
+
+	if err := s.initLogger(); err != nil {
+		return fmt.Errorf("cannot init logger: %w", err)
+	}
+
+	// The storage drops expired mutes and silences by the
+	// clock of the replay, so it is always kept in memory
+	storage := reportstorage.NewInMemoryReportStorage()
+	s.reportStorage = storage
+
+	if err := s.addConfiguredSilences(); err != nil {
+		return fmt.Errorf("cannot add configured silences: %w", err)
+	}
+
+	events, err := replay.ReadEvents(reader)
+
+	if err != nil {
+		return err
 	}
 
-	_, err = s.getReportEndpoints()
+	reporter, err := s.getReporter()
 
 	if err != nil {
 		return err
 	}
 
-	if err := http2.ListenAndServe(":9090", nil); err != nil {
-		panic(err)
+	// The listener only processes the given events,
+	// so it doesn't need the kubernetes api.
+	eventListener := eventctx.NewEventListener(reporter, nil, s.config.WarnOnEventReasons, s.config.WarnOnReachCount, s.getLogger())
+	replayer := replay.NewReplayer(eventListener, reporter, s.getLogger())
+	storage.SetClock(replayer.Now)
+	s.getPrinter().SetClock(replayer.Now)
+
+	return replayer.Replay(events)
+}
+
+// shutdown stops the http server first, so no new notifications are
+// created, and delivers the queued notifications afterwards.
+func (s *Server) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.getShutdownTimeout())
+	defer cancel()
+
+	var errs []error
+
+	if err := s.getHttpServer().Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("cannot stop http server: %w", err))
+	}
+
+	// The listeners may still create reports in their current cycle.
+	if err := s.supervisor.Wait(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, q := range s.queues {
+		if err := q.Drain(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.emailNotifier != nil {
+		if err := s.emailNotifier.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("cannot send email digest: %w", err))
+		}
+	}
+
+	for _, err := range errs {
+		s.getLogger().Error("shutdown failed", logging.Err(err))
+	}
+
+	if len(errs) > 0 {
+		return errors.New("shutdown was incomplete")
 	}
 
 	return nil